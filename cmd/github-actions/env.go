@@ -0,0 +1,306 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v70/github"
+
+	"github.com/FerretDB/github-actions/internal"
+)
+
+// params contains command-line parameters used to synthesize GitHub Actions environment.
+type params struct {
+	repo      string            // like "FerretDB/FerretDB"
+	event     string            // like "pull_request"; derived from other parameters if empty
+	eventFile string            // existing event payload
+	pr        int               // PR number
+	ref       string            // like "refs/heads/main", "refs/tags/v1.2.3", or "main"
+	sha       string            // commit SHA
+	actor     string            // GitHub login
+	workspace string            // GITHUB_WORKSPACE
+	inputs    map[string]string // action inputs
+	debug     bool              // enables debug logging
+}
+
+// files contains paths of files written by the action.
+type files struct {
+	event   string // GITHUB_EVENT_PATH
+	output  string // GITHUB_OUTPUT
+	summary string // GITHUB_STEP_SUMMARY
+	env     string // GITHUB_ENV
+}
+
+// eventName returns the event name for the given parameters.
+func (p *params) eventName() string {
+	switch {
+	case p.event != "":
+		return p.event
+	case p.pr != 0:
+		return "pull_request"
+	case p.ref != "":
+		return "push"
+	default:
+		return "workflow_dispatch"
+	}
+}
+
+// fullRef returns fully-qualified ref like "refs/heads/main".
+func (p *params) fullRef() string {
+	switch {
+	case p.ref == "":
+		return ""
+	case strings.HasPrefix(p.ref, "refs/"):
+		return p.ref
+	default:
+		return "refs/heads/" + p.ref
+	}
+}
+
+// environment returns environment variables for the action,
+// writing a synthesized event payload (unless given) and empty output files to dir.
+//
+// PR is required for `pull_request` and `pull_request_target` events without event file.
+func environment(p *params, pr *github.PullRequest, dir string) (map[string]string, *files, error) {
+	owner, name, ok := strings.Cut(p.repo, "/")
+	if !ok || owner == "" || name == "" {
+		return nil, nil, fmt.Errorf("environment: repository should be in owner/name format, got %q", p.repo)
+	}
+
+	f := &files{
+		event:   p.eventFile,
+		output:  filepath.Join(dir, "output"),
+		summary: filepath.Join(dir, "step_summary.md"),
+		env:     filepath.Join(dir, "env"),
+	}
+
+	for _, path := range []string{f.output, f.summary, f.env} {
+		if err := os.WriteFile(path, nil, 0o666); err != nil {
+			return nil, nil, fmt.Errorf("environment: %w", err)
+		}
+	}
+
+	eventName := p.eventName()
+	ref := p.fullRef()
+	sha := p.sha
+
+	env := map[string]string{
+		"GITHUB_EVENT_NAME":   eventName,
+		"GITHUB_REPOSITORY":   p.repo,
+		"GITHUB_ACTOR":        p.actor,
+		"GITHUB_WORKSPACE":    p.workspace,
+		"GITHUB_OUTPUT":       f.output,
+		"GITHUB_STEP_SUMMARY": f.summary,
+		"GITHUB_ENV":          f.env,
+		"GITHUB_SERVER_URL":   "https://github.com",
+		"GITHUB_API_URL":      "https://api.github.com",
+		"GITHUB_GRAPHQL_URL":  "https://api.github.com/graphql",
+	}
+
+	for k, v := range p.inputs {
+		env["INPUT_"+strings.ToUpper(strings.ReplaceAll(k, " ", "_"))] = v
+	}
+
+	if p.debug {
+		env["RUNNER_DEBUG"] = "1"
+	}
+
+	repo := &github.Repository{
+		Owner:    &github.User{Login: github.Ptr(owner)},
+		Name:     github.Ptr(name),
+		FullName: github.Ptr(p.repo),
+	}
+
+	var payload any
+
+	switch eventName {
+	case "pull_request", "pull_request_target":
+		if p.eventFile != "" {
+			break
+		}
+
+		if pr == nil {
+			return nil, nil, fmt.Errorf("environment: PR is required for %q event", eventName)
+		}
+
+		payload = &github.PullRequestEvent{
+			Action:      github.Ptr("synchronize"),
+			Number:      pr.Number,
+			PullRequest: pr,
+			Repo:        pr.GetBase().GetRepo(),
+			Sender:      pr.GetUser(),
+		}
+
+		env["GITHUB_HEAD_REF"] = pr.GetHead().GetRef()
+		env["GITHUB_BASE_REF"] = pr.GetBase().GetRef()
+
+		if ref == "" {
+			ref = fmt.Sprintf("refs/pull/%d/merge", pr.GetNumber())
+		}
+
+		if sha == "" {
+			sha = pr.GetHead().GetSHA()
+		}
+
+	case "push":
+		payload = &github.PushEvent{
+			Ref:   github.Ptr(ref),
+			After: github.Ptr(sha),
+			Repo: &github.PushEventRepository{
+				Owner:    &github.User{Login: github.Ptr(owner)},
+				Name:     github.Ptr(name),
+				FullName: github.Ptr(p.repo),
+			},
+		}
+
+	case "schedule":
+		payload = &internal.ScheduleEvent{
+			Schedule: "0 0 * * *",
+			Repo:     repo,
+		}
+
+	case "workflow_dispatch":
+		inputs, err := json.Marshal(p.inputs)
+		if err != nil {
+			return nil, nil, fmt.Errorf("environment: %w", err)
+		}
+
+		payload = &github.WorkflowDispatchEvent{
+			Ref:    github.Ptr(ref),
+			Inputs: inputs,
+			Repo:   repo,
+		}
+
+	default:
+		if p.eventFile == "" {
+			return nil, nil, fmt.Errorf("environment: event file is required for %q event", eventName)
+		}
+	}
+
+	if payload != nil && p.eventFile == "" {
+		b, err := json.MarshalIndent(payload, "", "  ")
+		if err != nil {
+			return nil, nil, fmt.Errorf("environment: %w", err)
+		}
+
+		f.event = filepath.Join(dir, "event.json")
+		if err = os.WriteFile(f.event, b, 0o666); err != nil {
+			return nil, nil, fmt.Errorf("environment: %w", err)
+		}
+	}
+
+	env["GITHUB_EVENT_PATH"] = f.event
+	env["GITHUB_REF"] = ref
+	env["GITHUB_SHA"] = sha
+
+	switch {
+	case strings.HasPrefix(ref, "refs/heads/"):
+		env["GITHUB_REF_TYPE"] = "branch"
+		env["GITHUB_REF_NAME"] = strings.TrimPrefix(ref, "refs/heads/")
+	case strings.HasPrefix(ref, "refs/tags/"):
+		env["GITHUB_REF_TYPE"] = "tag"
+		env["GITHUB_REF_NAME"] = strings.TrimPrefix(ref, "refs/tags/")
+	case strings.HasPrefix(ref, "refs/pull/"):
+		env["GITHUB_REF_NAME"] = strings.TrimPrefix(ref, "refs/pull/")
+	}
+
+	return env, f, nil
+}
+
+// parseOutputs parses GITHUB_OUTPUT file content.
+//
+// Both `name=value` and `name<<DELIMITER` formats are supported.
+func parseOutputs(b []byte) (map[string]string, error) {
+	res := make(map[string]string)
+
+	s := bufio.NewScanner(bytes.NewReader(b))
+	for s.Scan() {
+		line := s.Text()
+		if line == "" {
+			continue
+		}
+
+		if name, delim, ok := strings.Cut(line, "<<"); ok {
+			var lines []string
+
+			for {
+				if !s.Scan() {
+					return nil, fmt.Errorf("parseOutputs: no delimiter %q for %q", delim, name)
+				}
+
+				if s.Text() == delim {
+					break
+				}
+
+				lines = append(lines, s.Text())
+			}
+
+			res[name] = strings.Join(lines, "\n")
+
+			continue
+		}
+
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("parseOutputs: invalid line %q", line)
+		}
+
+		res[name] = value
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("parseOutputs: %w", err)
+	}
+
+	return res, nil
+}
+
+// parseInputs parses `name=value` pairs.
+func parseInputs(pairs []string) (map[string]string, error) {
+	res := make(map[string]string, len(pairs))
+
+	for _, pair := range pairs {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("parseInputs: input should be in name=value format, got %q", pair)
+		}
+
+		res[name] = value
+	}
+
+	return res, nil
+}
+
+// prNumber returns PR number from the given string like "123" or "#123".
+func prNumber(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+
+	n, err := strconv.Atoi(strings.TrimPrefix(s, "#"))
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("prNumber: invalid PR number %q", s)
+	}
+
+	return n, nil
+}
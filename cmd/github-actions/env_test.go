@@ -0,0 +1,204 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-github/v70/github"
+	"github.com/sethvargo/go-githubactions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/github-actions/internal"
+)
+
+func TestEnvironment(t *testing.T) {
+	t.Parallel()
+
+	pr := &github.PullRequest{
+		Number: github.Ptr(42),
+		User:   &github.User{Login: github.Ptr("AlekSi")},
+		Head: &github.PullRequestBranch{
+			Ref: github.Ptr("dependabot/go_modules/x"),
+			SHA: github.Ptr("0123456789abcdef0123456789abcdef01234567"),
+		},
+		Base: &github.PullRequestBranch{
+			Ref: github.Ptr("main"),
+			Repo: &github.Repository{
+				Owner: &github.User{Login: github.Ptr("FerretDB")},
+				Name:  github.Ptr("FerretDB"),
+			},
+		},
+	}
+
+	cases := []struct {
+		name             string
+		params           params
+		pr               *github.PullRequest
+		expectedEnv      map[string]string // only those keys are checked
+		expectedPRNumber int
+		expectedHeadSHA  string
+		expectedErr      string
+	}{{
+		name: "Tag",
+		params: params{
+			repo: "FerretDB/FerretDB",
+			ref:  "refs/tags/v1.2.3",
+		},
+		expectedEnv: map[string]string{
+			"GITHUB_EVENT_NAME": "push",
+			"GITHUB_REPOSITORY": "FerretDB/FerretDB",
+			"GITHUB_REF":        "refs/tags/v1.2.3",
+			"GITHUB_REF_TYPE":   "tag",
+			"GITHUB_REF_NAME":   "v1.2.3",
+		},
+	}, {
+		name: "Branch",
+		params: params{
+			repo: "FerretDB/FerretDB",
+			ref:  "main",
+			sha:  "0123456789abcdef0123456789abcdef01234567",
+		},
+		expectedEnv: map[string]string{
+			"GITHUB_EVENT_NAME": "push",
+			"GITHUB_REF":        "refs/heads/main",
+			"GITHUB_REF_TYPE":   "branch",
+			"GITHUB_REF_NAME":   "main",
+		},
+		expectedHeadSHA: "0123456789abcdef0123456789abcdef01234567",
+	}, {
+		name: "PullRequest",
+		params: params{
+			repo:  "FerretDB/FerretDB",
+			pr:    42,
+			debug: true,
+		},
+		pr: pr,
+		expectedEnv: map[string]string{
+			"GITHUB_EVENT_NAME": "pull_request",
+			"GITHUB_HEAD_REF":   "dependabot/go_modules/x",
+			"GITHUB_BASE_REF":   "main",
+			"GITHUB_REF":        "refs/pull/42/merge",
+			"GITHUB_SHA":        "0123456789abcdef0123456789abcdef01234567",
+			"RUNNER_DEBUG":      "1",
+		},
+		expectedPRNumber: 42,
+		expectedHeadSHA:  "0123456789abcdef0123456789abcdef01234567",
+	}, {
+		name: "PullRequestNoPR",
+		params: params{
+			repo: "FerretDB/FerretDB",
+			pr:   42,
+		},
+		expectedErr: `environment: PR is required for "pull_request" event`,
+	}, {
+		name: "WorkflowDispatch",
+		params: params{
+			repo:   "FerretDB/FerretDB",
+			inputs: map[string]string{"pr-number": "42", "check-runs": "true"},
+		},
+		expectedEnv: map[string]string{
+			"GITHUB_EVENT_NAME": "workflow_dispatch",
+			"INPUT_PR-NUMBER":   "42",
+			"INPUT_CHECK-RUNS":  "true",
+		},
+	}, {
+		name: "EventFile",
+		params: params{
+			repo:      "AlekSi/FerretDB",
+			event:     "merge_group",
+			eventFile: filepath.Join("..", "..", "testdata", "merge_group.json"),
+		},
+		expectedEnv: map[string]string{
+			"GITHUB_EVENT_NAME": "merge_group",
+		},
+		expectedPRNumber: 10,
+		expectedHeadSHA:  "4b1d1b0b4b3c2a6f3e1e4b8f9a9c6d1e2f3a4b5c",
+	}, {
+		name: "NoEventFile",
+		params: params{
+			repo:  "FerretDB/FerretDB",
+			event: "release",
+		},
+		expectedErr: `environment: event file is required for "release" event`,
+	}, {
+		name: "InvalidRepo",
+		params: params{
+			repo: "FerretDB",
+		},
+		expectedErr: `environment: repository should be in owner/name format, got "FerretDB"`,
+	}}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			env, f, err := environment(&tc.params, tc.pr, t.TempDir())
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+
+			for k, v := range tc.expectedEnv {
+				assert.Equal(t, v, env[k], k)
+			}
+
+			assert.Equal(t, f.event, env["GITHUB_EVENT_PATH"])
+			assert.Equal(t, f.output, env["GITHUB_OUTPUT"])
+			assert.Equal(t, f.summary, env["GITHUB_STEP_SUMMARY"])
+
+			// synthesized event should be readable by actions
+			action := githubactions.New(
+				githubactions.WithWriter(io.Discard),
+				githubactions.WithGetenv(func(key string) string { return env[key] }),
+			)
+
+			event, err := internal.ReadEvent(action)
+			require.NoError(t, err)
+
+			owner, repo := event.Repo()
+			assert.Equal(t, tc.params.repo, owner+"/"+repo)
+			assert.Equal(t, tc.expectedPRNumber, event.PRNumber())
+			assert.Equal(t, tc.expectedHeadSHA, event.HeadSHA())
+		})
+	}
+}
+
+func TestParseOutputs(t *testing.T) {
+	t.Parallel()
+
+	b := []byte("owner=FerretDB\n" +
+		"summary<<ghadelimiter_1234\nfirst line\nsecond=line\nghadelimiter_1234\n" +
+		"\n" +
+		"empty=\n")
+
+	actual, err := parseOutputs(b)
+	require.NoError(t, err)
+
+	expected := map[string]string{
+		"owner":   "FerretDB",
+		"summary": "first line\nsecond=line",
+		"empty":   "",
+	}
+	assert.Equal(t, expected, actual)
+
+	_, err = parseOutputs([]byte("summary<<EOF\nno delimiter\n"))
+	assert.EqualError(t, err, `parseOutputs: no delimiter "EOF" for "summary"`)
+}
@@ -0,0 +1,262 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command github-actions runs our actions locally, outside of GitHub Actions.
+//
+// It synthesizes GitHub Actions environment (event payload, `GITHUB_*` variables, inputs, output files)
+// from flags, runs the action in-process, and prints its outputs and step summary.
+//
+// Usage:
+//
+//	go run ./cmd/github-actions conform-pr -repo FerretDB/FerretDB -pr 123
+//	go run ./cmd/github-actions extract-docker-tag -repo FerretDB/FerretDB -ref refs/tags/v1.2.3
+//	go run ./cmd/github-actions detect-matching-pr -repo FerretDB/dance -event-file event.json -event pull_request
+//
+// GITHUB_TOKEN and other tokens are taken from the environment.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/google/go-github/v70/github"
+	"github.com/sethvargo/go-githubactions"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+
+	conformpr "github.com/FerretDB/github-actions/conform-pr"
+	detectmatchingpr "github.com/FerretDB/github-actions/detect-matching-pr"
+	extractdockertag "github.com/FerretDB/github-actions/extract-docker-tag"
+	extractfirsturl "github.com/FerretDB/github-actions/extract-first-url"
+	"github.com/FerretDB/github-actions/internal"
+	restartpractions "github.com/FerretDB/github-actions/restart-pr-actions"
+	setupgo "github.com/FerretDB/github-actions/setup-go"
+)
+
+// command is an action that could be run.
+type command struct {
+	description string
+	run         func(context.Context, *githubactions.Action) error
+}
+
+// commands contains actions that could be run.
+var commands = map[string]command{
+	"conform-pr":         {"Checks that PR conforms to our process guides", conformpr.Run},
+	"detect-matching-pr": {"Detects matching PR or branch in FerretDB or dance repository", detectmatchingpr.Run},
+	"extract-docker-tag": {"Extracts Docker tag from the Git and GitHub meta-information", extractdockertag.Run},
+	"extract-first-url":  {"Extracts the first URL from deploy.txt file", extractfirsturl.Run},
+	"restart-pr-actions": {"Restarts PR or branch actions", restartpractions.Run},
+	"setup-go":           {"Checks Go environment and downloads modules", setupgo.Run},
+}
+
+// stringsFlag is a flag.Value that collects repeated flags.
+type stringsFlag []string
+
+// String implements the flag.Value interface.
+func (s *stringsFlag) String() string {
+	return strings.Join(*s, ", ")
+}
+
+// Set implements the flag.Value interface.
+func (s *stringsFlag) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
+
+func main() {
+	log.SetFlags(0)
+
+	flag.Usage = usage
+	flag.Parse()
+
+	command := flag.Arg(0)
+	if _, ok := commands[command]; !ok {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var p params
+	var prS string
+	var inputs stringsFlag
+
+	fs := flag.NewFlagSet(command, flag.ExitOnError)
+	fs.StringVar(&p.repo, "repo", os.Getenv("GITHUB_REPOSITORY"), "repository in owner/name format")
+	fs.StringVar(&p.event, "event", "", "event name; defaults to `pull_request` with -pr, `push` with -ref, `workflow_dispatch` otherwise") //nolint:lll // for readability
+	fs.StringVar(&p.eventFile, "event-file", "", "event payload file; synthesized if not set")
+	fs.StringVar(&prS, "pr", "", "PR number; PR is fetched with GITHUB_TOKEN to synthesize event")
+	fs.StringVar(&p.ref, "ref", "", "Git ref like `refs/tags/v1.2.3`; branch name is also accepted")
+	fs.StringVar(&p.sha, "sha", "", "commit SHA")
+	fs.StringVar(&p.actor, "actor", "", "GitHub login of the actor")
+	fs.StringVar(&p.workspace, "workspace", wd, "workspace directory")
+	fs.Var(&inputs, "input", "action input in `name=value` format; may be repeated")
+	fs.BoolVar(&p.debug, "debug", false, "enable debug logging")
+
+	fs.Parse(flag.Args()[1:])
+
+	if p.pr, err = prNumber(prS); err != nil {
+		log.Fatal(err)
+	}
+
+	if p.inputs, err = parseInputs(inputs); err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	err = run(ctx, command, &p)
+
+	stop()
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// usage prints usage information.
+func usage() {
+	out := flag.CommandLine.Output()
+
+	fmt.Fprintf(out, "Usage: github-actions <command> [flags]\n\n")
+	fmt.Fprintf(out, "Commands:\n")
+
+	names := maps.Keys(commands)
+	slices.Sort(names)
+
+	for _, name := range names {
+		fmt.Fprintf(out, "  %-20s %s\n", name, commands[name].description)
+	}
+
+	fmt.Fprintf(out, "\nRun `github-actions <command> -h` for flags.\n")
+}
+
+// run synthesizes GitHub Actions environment, runs the action, and prints its outputs and summary.
+func run(ctx context.Context, command string, p *params) error {
+	dir, err := os.MkdirTemp("", "github-actions-")
+	if err != nil {
+		return fmt.Errorf("run: %w", err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	var pr *github.PullRequest
+	if ev := p.eventName(); p.pr != 0 && p.eventFile == "" && (ev == "pull_request" || ev == "pull_request_target") {
+		if pr, err = fetchPR(ctx, p); err != nil {
+			return fmt.Errorf("run: %w", err)
+		}
+	}
+
+	// most actions take PR number as an input for other events
+	if p.pr != 0 && p.inputs["pr-number"] == "" {
+		if p.inputs == nil {
+			p.inputs = make(map[string]string)
+		}
+
+		p.inputs["pr-number"] = fmt.Sprint(p.pr)
+	}
+
+	env, f, err := environment(p, pr, dir)
+	if err != nil {
+		return fmt.Errorf("run: %w", err)
+	}
+
+	keys := maps.Keys(env)
+	slices.Sort(keys)
+
+	// the process environment is used both by the action and by commands it runs, like `git`
+	for _, k := range keys {
+		if err = os.Setenv(k, env[k]); err != nil {
+			return fmt.Errorf("run: %w", err)
+		}
+
+		if p.debug {
+			log.Printf("%s=%s", k, internal.RedactEnv(k, env[k]))
+		}
+	}
+
+	log.Printf("Running %s ...", command)
+
+	runErr := commands[command].run(ctx, githubactions.New())
+
+	if err = printResults(f); err != nil {
+		return fmt.Errorf("run: %w", err)
+	}
+
+	if runErr != nil {
+		return fmt.Errorf("run: %s: %w", command, runErr)
+	}
+
+	return nil
+}
+
+// fetchPR fetches PR for event synthesis.
+func fetchPR(ctx context.Context, p *params) (*github.PullRequest, error) {
+	owner, repo, _ := strings.Cut(p.repo, "/")
+
+	client, err := internal.GitHubClient(ctx, githubactions.New(), "GITHUB_TOKEN")
+	if err != nil {
+		return nil, fmt.Errorf("fetchPR: %w", err)
+	}
+
+	pr, _, err := client.PullRequests.Get(ctx, owner, repo, p.pr)
+	if err != nil {
+		return nil, fmt.Errorf("fetchPR: %w", err)
+	}
+
+	return pr, nil
+}
+
+// printResults prints action's outputs and step summary.
+func printResults(f *files) error {
+	b, err := os.ReadFile(f.output)
+	if err != nil {
+		return fmt.Errorf("printResults: %w", err)
+	}
+
+	outputs, err := parseOutputs(b)
+	if err != nil {
+		return fmt.Errorf("printResults: %w", err)
+	}
+
+	if len(outputs) > 0 {
+		fmt.Printf("\nOutputs:\n")
+
+		names := maps.Keys(outputs)
+		slices.Sort(names)
+
+		for _, name := range names {
+			fmt.Printf("  %s=%s\n", name, outputs[name])
+		}
+	}
+
+	if b, err = os.ReadFile(f.summary); err != nil {
+		return fmt.Errorf("printResults: %w", err)
+	}
+
+	if summary := strings.TrimSpace(string(b)); summary != "" {
+		fmt.Printf("\nStep summary:\n\n%s\n", summary)
+	}
+
+	return nil
+}
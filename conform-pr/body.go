@@ -0,0 +1,147 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conformpr
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/sethvargo/go-githubactions"
+)
+
+var (
+	// HTML comments are used in PR templates for instructions.
+	commentRegexp = regexp.MustCompile(`(?s)<!--.*?-->`)
+
+	// ATX headings like "## Readiness checklist".
+	headingRegexp = regexp.MustCompile(`^ {0,3}#{1,6}\s+(.*?)(\s+#+)?\s*$`)
+
+	// task list items like "- [x] I added tests."
+	taskRegexp = regexp.MustCompile(`^\s*[-*+]\s+\[([ xX])\]\s+(.*)$`)
+
+	// closing keywords with issue references like "Closes #123"
+	// https://docs.github.com/en/issues/tracking-your-work-with-issues/linking-a-pull-request-to-an-issue
+	//nolint:lll // that URL is long
+	issueRegexp = regexp.MustCompile(`(?i)\b(close[sd]?|fix(e[sd])?|resolve[sd]?)\s*:?\s+(([\w.-]+/[\w.-]+)?#\d+|https://github\.com/[\w.-]+/[\w.-]+/issues/\d+)\b`)
+)
+
+// markdownTask is a task list item.
+type markdownTask struct {
+	text string
+	done bool
+}
+
+// markdownBody contains parts of PR body relevant for template validation.
+type markdownBody struct {
+	headings []string
+	tasks    []markdownTask
+	text     string // without comments and code blocks
+}
+
+// parseBody parses Markdown PR body, ignoring HTML comments and fenced code blocks.
+func parseBody(body string) *markdownBody {
+	body = strings.ReplaceAll(body, "\r\n", "\n")
+	body = commentRegexp.ReplaceAllString(body, "")
+
+	var res markdownBody
+	var text []string
+	var fence string
+
+	for _, line := range strings.Split(body, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			continue
+		}
+
+		text = append(text, line)
+
+		if m := headingRegexp.FindStringSubmatch(line); m != nil {
+			res.headings = append(res.headings, m[1])
+			continue
+		}
+
+		if m := taskRegexp.FindStringSubmatch(line); m != nil {
+			res.tasks = append(res.tasks, markdownTask{
+				text: strings.TrimSpace(m[2]),
+				done: m[1] != " ",
+			})
+		}
+	}
+
+	res.text = strings.Join(text, "\n")
+
+	return &res
+}
+
+// checkBodyTemplate checks that PR body follows the repository's PR template.
+//
+// Every violation is returned as a separate error.
+func checkBodyTemplate(_ *githubactions.Action, cfg *templateConfig, body string) []error {
+	b := parseBody(body)
+
+	var res []error
+
+	for _, section := range cfg.Sections {
+		var found bool
+
+		for _, h := range b.headings {
+			if strings.EqualFold(h, section) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			res = append(res, fmt.Errorf("PR body must have %q section.", section))
+		}
+	}
+
+	if cfg.Checklist {
+		for _, task := range b.tasks {
+			if task.done || isNotApplicable(task.text) {
+				continue
+			}
+
+			res = append(res, fmt.Errorf("Checklist item must be done or marked as N/A: %q.", task.text))
+		}
+	}
+
+	if cfg.IssueReference && !issueRegexp.MatchString(b.text) {
+		res = append(res, fmt.Errorf("PR body must reference an issue like `Closes #123`."))
+	}
+
+	return res
+}
+
+// isNotApplicable returns true if task list item is explicitly marked as not applicable
+// with "N/A" or strikethrough.
+func isNotApplicable(text string) bool {
+	if strings.Contains(strings.ToUpper(text), "N/A") {
+		return true
+	}
+
+	return strings.HasPrefix(text, "~~") && strings.HasSuffix(text, "~~")
+}
@@ -0,0 +1,124 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conformpr
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sethvargo/go-githubactions"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBody(t *testing.T) {
+	t.Parallel()
+
+	body := "<!-- Describe your changes. -->\r\n" +
+		"## Description\r\n" +
+		"\r\n" +
+		"Closes #1.\r\n" +
+		"\r\n" +
+		"```go\r\n" +
+		"## Not a heading\r\n" +
+		"- [ ] not a task\r\n" +
+		"```\r\n" +
+		"\r\n" +
+		"### Readiness checklist ###\r\n" +
+		"\r\n" +
+		"- [x] I added tests.\r\n" +
+		"* [ ] I updated documentation.\r\n" +
+		"<!--\r\n" +
+		"- [ ] commented out\r\n" +
+		"-->\r\n"
+
+	expected := &markdownBody{
+		headings: []string{"Description", "Readiness checklist"},
+		tasks: []markdownTask{
+			{text: "I added tests.", done: true},
+			{text: "I updated documentation."},
+		},
+		text: "\n## Description\n\nCloses #1.\n\n\n### Readiness checklist ###\n\n" +
+			"- [x] I added tests.\n* [ ] I updated documentation.\n\n",
+	}
+	assert.Equal(t, expected, parseBody(body))
+}
+
+func TestCheckBodyTemplate(t *testing.T) {
+	t.Parallel()
+
+	cfg := &templateConfig{
+		Sections:       []string{"Description", "Readiness checklist"},
+		Checklist:      true,
+		IssueReference: true,
+	}
+
+	cases := []struct {
+		name     string
+		body     string
+		expected []error
+	}{{
+		name: "Valid",
+		body: "## Description\n\nFixes FerretDB/FerretDB#123.\n\n" +
+			"## readiness checklist\n\n" +
+			"- [x] I added tests.\n" +
+			"- [ ] I updated documentation (N/A).\n" +
+			"- [ ] ~~I ran benchmarks.~~\n",
+	}, {
+		name: "IssueURL",
+		body: "## Description\n\nResolves https://github.com/FerretDB/FerretDB/issues/123.\n\n" +
+			"## Readiness checklist\n",
+	}, {
+		name: "Empty",
+		body: "",
+		expected: []error{
+			errors.New(`PR body must have "Description" section.`),
+			errors.New(`PR body must have "Readiness checklist" section.`),
+			errors.New("PR body must reference an issue like `Closes #123`."),
+		},
+	}, {
+		name: "UncheckedItems",
+		body: "## Description\n\nCloses #123.\n\n" +
+			"## Readiness checklist\n\n" +
+			"- [ ] I added tests.\n" +
+			"- [x] I updated documentation.\n" +
+			"- [ ] I ran ~~benchmarks~~.\n",
+		expected: []error{
+			errors.New(`Checklist item must be done or marked as N/A: "I added tests.".`),
+			errors.New(`Checklist item must be done or marked as N/A: "I ran ~~benchmarks~~.".`),
+		},
+	}, {
+		name: "IgnoredParts",
+		body: "<!-- ## Description -->\n\n" +
+			"```\n## Readiness checklist\nCloses #123.\n```\n\n" +
+			"<!--\n- [ ] I added tests.\n-->\n" +
+			"See #123.\n",
+		expected: []error{
+			errors.New(`PR body must have "Description" section.`),
+			errors.New(`PR body must have "Readiness checklist" section.`),
+			errors.New("PR body must reference an issue like `Closes #123`."),
+		},
+	}}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			action := githubactions.New()
+			actual := checkBodyTemplate(action, cfg, tc.body)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
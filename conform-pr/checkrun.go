@@ -0,0 +1,147 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conformpr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v70/github"
+	"github.com/sethvargo/go-githubactions"
+	"golang.org/x/exp/slices"
+)
+
+// checkRunPrefix is a prefix of check run names, like "Conform PR / Title".
+const checkRunPrefix = "Conform PR / "
+
+// Check run conclusions.
+//
+// See https://docs.github.com/en/rest/checks/runs#create-a-check-run.
+const (
+	conclusionSuccess = "success"
+	conclusionFailure = "failure"
+	conclusionNeutral = "neutral"
+)
+
+// checkRun contains data of a single check run.
+type checkRun struct {
+	name       string
+	conclusion string
+	title      string
+	skipped    bool          // true if the check was not run
+	errs       []checkResult // failed checks with their severities
+}
+
+// checkRuns groups report results into check runs, one per known check.
+//
+// Checks that were not run (disabled, or skipped for PRs like dependabot's) are reported as successful,
+// so branch protection rules that require them do not block PRs forever.
+//
+// Only failed checks with error severity fail check runs; others are neutral.
+// Community PRs are not treated specially there: use `community_severity` for that.
+func (r *report) checkRuns() []checkRun {
+	runs := make([]checkRun, len(checkNames))
+	for i, name := range checkNames {
+		runs[i] = checkRun{
+			name:       checkRunPrefix + name,
+			conclusion: conclusionSuccess,
+			title:      "Skipped",
+			skipped:    true,
+		}
+	}
+
+	for _, res := range r.results {
+		i := slices.Index(checkNames, res.check)
+		if i < 0 {
+			panic(fmt.Sprintf("unexpected check %q", res.check))
+		}
+
+		run := &runs[i]
+
+		if run.skipped {
+			run.title = "Passed"
+			run.skipped = false
+		}
+
+		if res.err == nil {
+			continue
+		}
+
+		run.errs = append(run.errs, res)
+		run.title = fmt.Sprintf("%d problem(s) found", len(run.errs))
+
+		if res.severity != severityError {
+			if run.conclusion == conclusionSuccess {
+				run.conclusion = conclusionNeutral
+			}
+
+			continue
+		}
+
+		run.conclusion = conclusionFailure
+	}
+
+	return runs
+}
+
+// createCheckRuns creates completed check runs for owner/repo@headSHA.
+//
+// Failed checks are listed in the output text with their severities.
+// Annotations are not used: GitHub attaches them to file lines, and PR problems are not in files.
+func createCheckRuns(ctx context.Context, action *githubactions.Action, client *github.Client, owner, repo, headSHA string, runs []checkRun) error { //nolint:lll // for readability
+	for _, run := range runs {
+		var text []string
+		for _, res := range run.errs {
+			text = append(text, "* "+res.severity.mark()+" "+res.err.Error())
+		}
+
+		var summary string
+		switch {
+		case run.conclusion == conclusionFailure:
+			summary = "❌ Check failed."
+		case len(run.errs) > 0:
+			summary = "⚠️ Check found problems that do not block the PR."
+		case run.skipped:
+			summary = "✅ Check was not run for that PR."
+		default:
+			summary = "✅ Check passed."
+		}
+
+		opts := github.CreateCheckRunOptions{
+			Name:       run.name,
+			HeadSHA:    headSHA,
+			Status:     github.Ptr("completed"),
+			Conclusion: github.Ptr(run.conclusion),
+			Output: &github.CheckRunOutput{
+				Title:   github.Ptr(run.title),
+				Summary: github.Ptr(summary),
+			},
+		}
+
+		if len(text) > 0 {
+			opts.Output.Text = github.Ptr(strings.Join(text, "\n"))
+		}
+
+		cr, _, err := client.Checks.CreateCheckRun(ctx, owner, repo, opts)
+		if err != nil {
+			return fmt.Errorf("createCheckRuns: %w", err)
+		}
+
+		action.Infof("Created check run %q (%s): %s", run.name, run.conclusion, cr.GetHTMLURL())
+	}
+
+	return nil
+}
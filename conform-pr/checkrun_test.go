@@ -0,0 +1,161 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conformpr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-github/v70/github"
+	"github.com/sethvargo/go-githubactions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/github-actions/internal"
+)
+
+func TestCheckRuns(t *testing.T) {
+	errLabels := errors.New("labels")
+	errBody := errors.New("body")
+
+	rep := &report{
+		results: []checkResult{
+			{check: "Labels", err: errLabels, severity: severityError},
+			{check: "Labels", err: errLabels, severity: severityError},
+			{check: "Title"},
+			{check: "Body", err: errBody, severity: severityWarning},
+		},
+	}
+
+	skipped := func(name string) checkRun {
+		return checkRun{name: "Conform PR / " + name, conclusion: conclusionSuccess, title: "Skipped", skipped: true}
+	}
+
+	expected := []checkRun{
+		{
+			name:       "Conform PR / Labels",
+			conclusion: conclusionFailure,
+			title:      "2 problem(s) found",
+			errs: []checkResult{
+				{check: "Labels", err: errLabels, severity: severityError},
+				{check: "Labels", err: errLabels, severity: severityError},
+			},
+		},
+		skipped("Size"),
+		skipped("Sprint"),
+		{
+			name:       "Conform PR / Title",
+			conclusion: conclusionSuccess,
+			title:      "Passed",
+		},
+		{
+			name:       "Conform PR / Body",
+			conclusion: conclusionNeutral,
+			title:      "1 problem(s) found",
+			errs:       []checkResult{{check: "Body", err: errBody, severity: severityWarning}},
+		},
+		skipped("Template"),
+		skipped("Issue"),
+		skipped("Auto-merge"),
+	}
+	assert.Equal(t, expected, rep.checkRuns())
+
+	// blocking checks still fail for community PRs; community_severity is used to soften others
+	rep.community = true
+	assert.Equal(t, expected, rep.checkRuns())
+
+	// skipped PRs like dependabot's still report all checks
+	runs := new(report).checkRuns()
+	require.Len(t, runs, len(checkNames))
+
+	for i, name := range checkNames {
+		assert.Equal(t, skipped(name), runs[i])
+	}
+}
+
+func TestCreateCheckRuns(t *testing.T) {
+	var created []github.CreateCheckRunOptions
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/repos/FerretDB/FerretDB/check-runs" {
+			http.Error(w, "unexpected request "+r.Method+" "+r.URL.Path, http.StatusNotFound)
+			return
+		}
+
+		var opts github.CreateCheckRunOptions
+		json.NewDecoder(r.Body).Decode(&opts)
+		created = append(created, opts)
+
+		w.Write([]byte(`{"id": 1}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(srv.URL + "/")
+
+	runs := []checkRun{{
+		name:       "Conform PR / Title",
+		conclusion: conclusionFailure,
+		title:      "2 problem(s) found",
+		errs: []checkResult{{
+			check:    "Title",
+			err:      errors.New("PR title must start with an uppercase letter."),
+			severity: severityError,
+		}, {
+			check:    "Title",
+			err:      errors.New("PR title should not end with a punctuation."),
+			severity: severityWarning,
+		}},
+	}}
+
+	err := createCheckRuns(context.Background(), githubactions.New(), client, "FerretDB", "FerretDB", "abc123", runs)
+	require.NoError(t, err)
+	require.Len(t, created, 1)
+
+	actual := created[0]
+	assert.Equal(t, "Conform PR / Title", actual.Name)
+	assert.Equal(t, "abc123", actual.HeadSHA)
+	assert.Equal(t, conclusionFailure, actual.GetConclusion())
+	assert.Equal(t, "❌ Check failed.", actual.Output.GetSummary())
+
+	expected := "* ❌ PR title must start with an uppercase letter.\n" +
+		"* ⚠️ PR title should not end with a punctuation."
+	assert.Equal(t, expected, actual.Output.GetText())
+	assert.Empty(t, actual.Output.Annotations)
+}
+
+func TestCreateCheckRunsForbidden(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message": "Resource not accessible by integration"}`, http.StatusForbidden)
+	}))
+	t.Cleanup(srv.Close)
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(srv.URL + "/")
+
+	runs := new(report).checkRuns()
+
+	err := createCheckRuns(context.Background(), githubactions.New(), client, "FerretDB", "FerretDB", "abc123", runs)
+	require.Error(t, err)
+	assert.True(t, internal.IsForbidden(err))
+
+	assert.False(t, internal.IsForbidden(errors.New("other")))
+	assert.False(t, internal.IsForbidden(&github.ErrorResponse{Message: "no response"}))
+}
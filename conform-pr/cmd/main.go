@@ -0,0 +1,35 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command conform-pr runs `conform-pr` action; see its action.yml.
+package main
+
+import (
+	"context"
+	"flag"
+
+	"github.com/sethvargo/go-githubactions"
+
+	conformpr "github.com/FerretDB/github-actions/conform-pr"
+)
+
+func main() {
+	flag.Parse()
+
+	action := githubactions.New()
+
+	if err := conformpr.Run(context.Background(), action); err != nil {
+		action.Fatalf("%s.", err)
+	}
+}
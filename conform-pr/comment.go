@@ -0,0 +1,152 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conformpr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v70/github"
+	"github.com/sethvargo/go-githubactions"
+
+	"github.com/FerretDB/github-actions/internal"
+)
+
+// commentMarker is a hidden marker that identifies conform-pr's sticky comment.
+const commentMarker = "<!-- conform-pr -->"
+
+// errReadOnly is returned when the token can't be used to write comments.
+var errReadOnly = errors.New("token is read-only")
+
+// postComment creates or updates the sticky PR comment with the given summary.
+//
+// Errors are logged as warnings: results are always available in the step summary.
+// Only comments written by login are updated.
+func postComment(ctx context.Context, action *githubactions.Action, client *github.Client, login string, pr *github.PullRequest, summary string, conform bool) { //nolint:lll // for readability
+	// `pull_request` events from forks get a read-only token
+	// https://docs.github.com/en/actions/security-guides/automatic-token-authentication#permissions-for-the-github_token
+	//nolint:lll // that URL is long
+	if action.Getenv("GITHUB_EVENT_NAME") == "pull_request" && pr.GetHead().GetRepo().GetFork() {
+		action.Infof("Not updating PR comment for a PR from a fork; results are available in the step summary.")
+		return
+	}
+
+	if client == nil {
+		action.Infof("GITHUB_TOKEN is not set, not updating PR comment; results are available in the step summary.")
+		return
+	}
+
+	if login == "" {
+		action.Infof("Token's login is unknown, not updating PR comment; results are available in the step summary.")
+		return
+	}
+
+	owner := pr.GetBase().GetRepo().GetOwner().GetLogin()
+	repo := pr.GetBase().GetRepo().GetName()
+	number := pr.GetNumber()
+
+	if err := updateComment(ctx, action, client, login, owner, repo, number, summary, conform); err != nil {
+		action.Warningf("Failed to update PR comment, results are available only in the step summary: %s.", err)
+	}
+}
+
+// commentBody returns the sticky comment's body.
+func commentBody(summary string, conform bool) string {
+	if conform {
+		return commentMarker + "\n✅ All checks pass.\n"
+	}
+
+	return commentMarker + "\n### Conform PR\n\n" + summary
+}
+
+// updateComment creates or updates the sticky comment on owner/repo#number.
+//
+// When the PR conforms, an existing comment is collapsed to a short note,
+// and a new comment is not created.
+// Only comments written by login are considered existing.
+func updateComment(ctx context.Context, action *githubactions.Action, client *github.Client, login, owner, repo string, number int, summary string, conform bool) error { //nolint:lll // for readability
+	existing, err := findComment(ctx, client, login, owner, repo, number)
+	if err != nil {
+		return fmt.Errorf("updateComment: %w", err)
+	}
+
+	body := commentBody(summary, conform)
+
+	switch {
+	case existing == nil && conform:
+		action.Infof("PR conforms, no comment to update.")
+		return nil
+
+	case existing == nil:
+		c, _, err := client.Issues.CreateComment(ctx, owner, repo, number, &github.IssueComment{Body: &body})
+		if err != nil {
+			return fmt.Errorf("updateComment: %w", commentErr(err))
+		}
+
+		action.Infof("Created PR comment %s.", c.GetHTMLURL())
+
+	case existing.GetBody() == body:
+		action.Infof("PR comment %s is up to date.", existing.GetHTMLURL())
+
+	default:
+		c, _, err := client.Issues.EditComment(ctx, owner, repo, existing.GetID(), &github.IssueComment{Body: &body})
+		if err != nil {
+			return fmt.Errorf("updateComment: %w", commentErr(err))
+		}
+
+		action.Infof("Updated PR comment %s.", c.GetHTMLURL())
+	}
+
+	return nil
+}
+
+// findComment returns the first comment with commentMarker written by login, or nil if there is none.
+//
+// Comments of other users with the marker are ignored, so PR authors can't hijack the sticky comment.
+func findComment(ctx context.Context, client *github.Client, login, owner, repo string, number int) (*github.IssueComment, error) { //nolint:lll // for readability
+	opts := &github.IssueListCommentsOptions{
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	for {
+		comments, resp, err := client.Issues.ListComments(ctx, owner, repo, number, opts)
+		if err != nil {
+			return nil, fmt.Errorf("findComment: %w", err)
+		}
+
+		for _, c := range comments {
+			if strings.EqualFold(c.GetUser().GetLogin(), login) && strings.HasPrefix(c.GetBody(), commentMarker) {
+				return c, nil
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return nil, nil
+}
+
+// commentErr wraps errReadOnly for responses that indicate the lack of write permission.
+func commentErr(err error) error {
+	if internal.IsForbidden(err) {
+		return fmt.Errorf("%w: %w", errReadOnly, err)
+	}
+
+	return err
+}
@@ -0,0 +1,152 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conformpr
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-github/v70/github"
+	"github.com/sethvargo/go-githubactions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// botLogin is the login of the token's bot in tests.
+const botLogin = "github-actions[bot]"
+
+// commentsServer is a fake GitHub REST API server for PR comments.
+type commentsServer struct {
+	m        sync.Mutex
+	comments []*github.IssueComment
+	status   int // for write requests, if set
+}
+
+// ServeHTTP implements http.Handler.
+func (s *commentsServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if r.Method != http.MethodGet && s.status != 0 {
+		w.WriteHeader(s.status)
+		w.Write([]byte(`{"message": "Resource not accessible by integration"}`))
+		return
+	}
+
+	var c github.IssueComment
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/repos/FerretDB/FerretDB/issues/1/comments":
+		json.NewEncoder(w).Encode(s.comments)
+		return
+
+	case r.Method == http.MethodPost && r.URL.Path == "/repos/FerretDB/FerretDB/issues/1/comments":
+		json.NewDecoder(r.Body).Decode(&c)
+		c.ID = github.Ptr(int64(len(s.comments) + 1))
+		c.User = &github.User{Login: github.Ptr(botLogin)}
+		s.comments = append(s.comments, &c)
+
+	case r.Method == http.MethodPatch && strings.HasPrefix(r.URL.Path, "/repos/FerretDB/FerretDB/issues/comments/"):
+		id, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/repos/FerretDB/FerretDB/issues/comments/"))
+		if id < 1 || id > len(s.comments) || s.comments[id-1].GetUser().GetLogin() != botLogin {
+			http.Error(w, "unexpected edit of comment "+strconv.Itoa(id), http.StatusForbidden)
+			return
+		}
+
+		json.NewDecoder(r.Body).Decode(&c)
+		s.comments[id-1].Body = c.Body
+
+	default:
+		http.Error(w, "unexpected request "+r.Method+" "+r.URL.Path, http.StatusNotFound)
+		return
+	}
+
+	json.NewEncoder(w).Encode(c)
+}
+
+func TestUpdateComment(t *testing.T) {
+	ctx := context.Background()
+	action := githubactions.New()
+
+	s := new(commentsServer)
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(srv.URL + "/")
+
+	// no comment for conforming PR
+	err := updateComment(ctx, action, client, botLogin, "FerretDB", "FerretDB", 1, "summary 1", true)
+	require.NoError(t, err)
+	assert.Empty(t, s.comments)
+
+	err = updateComment(ctx, action, client, botLogin, "FerretDB", "FerretDB", 1, "summary 2", false)
+	require.NoError(t, err)
+	require.Len(t, s.comments, 1)
+	assert.Equal(t, commentBody("summary 2", false), s.comments[0].GetBody())
+
+	// re-run should not create duplicates
+	err = updateComment(ctx, action, client, botLogin, "FerretDB", "FerretDB", 1, "summary 3", false)
+	require.NoError(t, err)
+	require.Len(t, s.comments, 1)
+	assert.Equal(t, commentBody("summary 3", false), s.comments[0].GetBody())
+
+	err = updateComment(ctx, action, client, botLogin, "FerretDB", "FerretDB", 1, "summary 4", true)
+	require.NoError(t, err)
+	require.Len(t, s.comments, 1)
+	assert.Equal(t, commentMarker+"\n✅ All checks pass.\n", s.comments[0].GetBody())
+
+	s.status = http.StatusForbidden
+	err = updateComment(ctx, action, client, botLogin, "FerretDB", "FerretDB", 1, "summary 5", false)
+	require.ErrorIs(t, err, errReadOnly)
+}
+
+func TestUpdateCommentForeign(t *testing.T) {
+	ctx := context.Background()
+	action := githubactions.New()
+
+	// PR author posted the marker first
+	foreign := &github.IssueComment{
+		ID:   github.Ptr(int64(1)),
+		Body: github.Ptr(commentBody("fake summary", false)),
+		User: &github.User{Login: github.Ptr("pr-author")},
+	}
+
+	s := &commentsServer{comments: []*github.IssueComment{foreign}}
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(srv.URL + "/")
+
+	err := updateComment(ctx, action, client, botLogin, "FerretDB", "FerretDB", 1, "summary 1", false)
+	require.NoError(t, err)
+	require.Len(t, s.comments, 2)
+	assert.Equal(t, commentBody("fake summary", false), s.comments[0].GetBody())
+	assert.Equal(t, commentBody("summary 1", false), s.comments[1].GetBody())
+
+	err = updateComment(ctx, action, client, botLogin, "FerretDB", "FerretDB", 1, "summary 2", false)
+	require.NoError(t, err)
+	require.Len(t, s.comments, 2)
+	assert.Equal(t, commentBody("fake summary", false), s.comments[0].GetBody())
+	assert.Equal(t, commentBody("summary 2", false), s.comments[1].GetBody())
+}
@@ -0,0 +1,315 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conformpr
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+
+	"github.com/sethvargo/go-githubactions"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+
+	"github.com/FerretDB/github-actions/internal"
+)
+
+// defaultConfigPath is the configuration file path relative to the workspace
+// that is used when `config` input is not set.
+const defaultConfigPath = ".github/conform-pr.yml"
+
+// configVersion is the only supported configuration file version.
+const configVersion = 1
+
+//go:embed default.yml
+var defaultConfigFile []byte
+
+// severity is a severity level of a failed check.
+type severity string
+
+// Severity levels.
+//
+// Only failed checks with error severity make PR non-conforming.
+const (
+	severityError   = severity("error")
+	severityWarning = severity("warning")
+	severityNotice  = severity("notice")
+)
+
+// knownSeverities contains all severity levels.
+var knownSeverities = []severity{severityError, severityWarning, severityNotice}
+
+// mark returns an emoji for the severity level used in Markdown reports.
+func (s severity) mark() string {
+	switch s {
+	case severityWarning:
+		return "⚠️"
+	case severityNotice:
+		return "ℹ️"
+	default:
+		return "❌"
+	}
+}
+
+// checkNames contains names of all checks in the order they are run.
+var checkNames = []string{"Labels", "Size", "Sprint", "Title", "Body", "Template", "Issue", "Auto-merge"}
+
+// knownChecks contains configuration keys of all checks; see checkNames.
+var knownChecks = []string{"labels", "size", "sprint", "title", "body", "template", "issue", "auto-merge"}
+
+// knownPermissions contains all repository permission levels.
+//
+// See https://docs.github.com/en/graphql/reference/enums#repositorypermission.
+var knownPermissions = []string{"READ", "TRIAGE", "WRITE", "MAINTAIN", "ADMIN"}
+
+// config represents conform-pr configuration file.
+//
+// See default.yml for an example.
+type config struct {
+	Version     int                    `yaml:"version"`
+	Maintainers maintainersConfig      `yaml:"maintainers"`
+	Checks      map[string]checkConfig `yaml:"checks"`
+	Labels      labelsConfig           `yaml:"labels"`
+	Title       titleConfig            `yaml:"title"`
+	Body        bodyConfig             `yaml:"body"`
+	Template    templateConfig         `yaml:"template"`
+	Issue       issueConfig            `yaml:"issue"`
+}
+
+// maintainersConfig configures how PR authors are recognized as maintainers.
+type maintainersConfig struct {
+	// Team is an organization team slug; its members are maintainers.
+	Team string `yaml:"team"`
+
+	// Permissions lists repository permission levels of maintainers.
+	Permissions []string `yaml:"permissions"`
+}
+
+// checkConfig configures a single check.
+//
+// Fields that are not set in the configuration file keep their built-in values.
+type checkConfig struct {
+	// Enabled is true if not set.
+	Enabled *bool `yaml:"enabled"`
+
+	// Severity is used for failed checks; error by default.
+	Severity severity `yaml:"severity"`
+
+	// CommunitySeverity is used for failed checks of PRs from the community;
+	// the same as Severity by default.
+	CommunitySeverity severity `yaml:"community_severity"`
+}
+
+// enabled returns true if the check should be run.
+func (cc checkConfig) enabled() bool {
+	return cc.Enabled == nil || *cc.Enabled
+}
+
+// severityFor returns severity of failed check for PRs from maintainers or the community.
+func (cc checkConfig) severityFor(community bool) severity {
+	if community && cc.CommunitySeverity != "" {
+		return cc.CommunitySeverity
+	}
+
+	return cc.Severity
+}
+
+// blockingLabel is a label that prevents PR from being merged.
+type blockingLabel struct {
+	Name    string `yaml:"name"`
+	Message string `yaml:"message"`
+}
+
+// labelsConfig configures "Labels" check.
+type labelsConfig struct {
+	Blocking          []blockingLabel `yaml:"blocking"`
+	Forbidden         []string        `yaml:"forbidden"`
+	ForbiddenPrefixes []string        `yaml:"forbidden_prefixes"`
+	Required          []string        `yaml:"required"`
+}
+
+// titleConfig configures "Title" check.
+type titleConfig struct {
+	MaxLength      int  `yaml:"max_length"`
+	NoPrefix       bool `yaml:"no_prefix"`
+	ImperativeVerb bool `yaml:"imperative_verb"`
+
+	// ExtraVerbs are accepted in addition to verbs.yml lexicon.
+	ExtraVerbs []string `yaml:"extra_verbs"`
+}
+
+// bodyConfig configures "Body" check.
+type bodyConfig struct {
+	FinalPunctuation bool `yaml:"final_punctuation"`
+}
+
+// templateConfig configures "Template" check.
+type templateConfig struct {
+	// Sections lists required Markdown headings, case-insensitive.
+	Sections []string `yaml:"sections"`
+
+	// Checklist requires all task list items to be done or marked as N/A.
+	Checklist bool `yaml:"checklist"`
+
+	// IssueReference requires a closing keyword with issue reference like "Closes #123".
+	IssueReference bool `yaml:"issue_reference"`
+}
+
+// issueConfig configures "Issue" check.
+type issueConfig struct {
+	// ExemptLabels lists labels of PRs that do not need a linked issue.
+	ExemptLabels []string `yaml:"exempt_labels"`
+}
+
+// parseConfig parses configuration file content over built-in configuration and validates it.
+//
+// Both YAML and JSON are accepted. Unknown fields are rejected.
+// Fields missing from the file keep their built-in values; lists are replaced, not appended.
+func parseConfig(b []byte) (*config, error) {
+	return decodeConfig(b, defaultConfig())
+}
+
+// decodeConfig decodes configuration file content over base configuration and validates it.
+//
+// Fields of checks are merged individually, so a check could be listed only to change its severity.
+func decodeConfig(b []byte, base *config) (*config, error) {
+	c := base
+	defaults := c.Checks
+
+	// version should be set explicitly, checks are merged below
+	c.Version = 0
+	c.Checks = nil
+
+	if err := internal.DecodeConfig(b, c); err != nil {
+		return nil, err
+	}
+
+	for name, dc := range defaults {
+		cc, ok := c.Checks[name]
+		if !ok {
+			cc = dc
+		}
+
+		if cc.Enabled == nil {
+			cc.Enabled = dc.Enabled
+		}
+
+		if cc.Severity == "" {
+			cc.Severity = dc.Severity
+		}
+
+		if cc.CommunitySeverity == "" {
+			cc.CommunitySeverity = dc.CommunitySeverity
+		}
+
+		if c.Checks == nil {
+			c.Checks = make(map[string]checkConfig, len(defaults))
+		}
+
+		c.Checks[name] = cc
+	}
+
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// validate checks that configuration is valid.
+func (c *config) validate() error {
+	if c.Version != configVersion {
+		return fmt.Errorf("unsupported version %d, expected %d", c.Version, configVersion)
+	}
+
+	// sort checks to make errors stable
+	names := maps.Keys(c.Checks)
+	slices.Sort(names)
+
+	for _, name := range names {
+		if !slices.Contains(knownChecks, name) {
+			return fmt.Errorf("checks: unknown check %q, expected one of: %s", name, strings.Join(knownChecks, ", "))
+		}
+
+		for _, sev := range []severity{c.Checks[name].Severity, c.Checks[name].CommunitySeverity} {
+			if sev != "" && !slices.Contains(knownSeverities, sev) {
+				return fmt.Errorf(
+					"checks.%s: unexpected severity %q, expected one of: %s, %s, %s",
+					name, sev, severityError, severityWarning, severityNotice,
+				)
+			}
+		}
+	}
+
+	for i, p := range c.Maintainers.Permissions {
+		if !slices.Contains(knownPermissions, p) {
+			return fmt.Errorf(
+				"maintainers.permissions[%d]: unexpected value %q, expected one of: %s",
+				i, p, strings.Join(knownPermissions, ", "),
+			)
+		}
+	}
+
+	for i, l := range c.Labels.Blocking {
+		if l.Name == "" {
+			return fmt.Errorf("labels.blocking[%d].name: must be set", i)
+		}
+
+		if l.Message == "" {
+			return fmt.Errorf("labels.blocking[%d].message: must be set", i)
+		}
+	}
+
+	if c.Title.MaxLength < 0 {
+		return fmt.Errorf("title.max_length: must not be negative, got %d", c.Title.MaxLength)
+	}
+
+	return nil
+}
+
+// check returns configuration for the check with the given name (like "Auto-merge").
+//
+// Checks missing from both configuration files are enabled with error severity.
+func (c *config) check(name string) checkConfig {
+	if cc, ok := c.Checks[strings.ToLower(name)]; ok {
+		if cc.Severity == "" {
+			cc.Severity = severityError
+		}
+
+		return cc
+	}
+
+	return checkConfig{
+		Severity: severityError,
+	}
+}
+
+// defaultConfig returns built-in configuration with FerretDB rules.
+func defaultConfig() *config {
+	c, err := decodeConfig(defaultConfigFile, new(config))
+	if err != nil {
+		panic(fmt.Sprintf("invalid default configuration: %s", err))
+	}
+
+	return c
+}
+
+// loadConfig loads configuration from the file set by `config` input
+// (relative to GITHUB_WORKSPACE).
+//
+// If input is not set and the default file does not exist, built-in configuration is returned.
+func loadConfig(action *githubactions.Action) (*config, error) {
+	return internal.LoadConfig(action, defaultConfigPath, parseConfig, defaultConfig)
+}
@@ -0,0 +1,157 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conformpr
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sethvargo/go-githubactions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/github-actions/internal/testutil"
+)
+
+func TestParseConfig(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Default", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := defaultConfig()
+		assert.Equal(t, 72, cfg.Title.MaxLength)
+		assert.Contains(t, cfg.Labels.Required, "code/bug")
+		assert.Equal(t, severityError, cfg.check("Auto-merge").severityFor(false))
+		assert.Equal(t, severityWarning, cfg.check("Auto-merge").severityFor(true))
+		assert.Equal(t, severityError, cfg.check("Title").severityFor(true))
+
+		require.Len(t, checkNames, len(knownChecks))
+		for _, name := range checkNames {
+			assert.Contains(t, knownChecks, strings.ToLower(name))
+		}
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		t.Parallel()
+
+		cfg, err := parseConfig([]byte(`{"version": 1, "checks": {"sprint": {"enabled": false}}}`))
+		require.NoError(t, err)
+		assert.False(t, cfg.check("Sprint").enabled())
+		assert.Equal(t, severityWarning, cfg.check("Sprint").severityFor(true))
+		assert.True(t, cfg.check("Size").enabled())
+		assert.Equal(t, severityError, cfg.check("Size").severityFor(false))
+	})
+
+	t.Run("SeverityOnly", func(t *testing.T) {
+		t.Parallel()
+
+		cfg, err := parseConfig([]byte("version: 1\nchecks:\n  sprint: {severity: warning}\n  template: {severity: notice}\n"))
+		require.NoError(t, err)
+
+		assert.True(t, cfg.check("Sprint").enabled())
+		assert.Equal(t, severityWarning, cfg.check("Sprint").severityFor(false))
+
+		// still disabled by built-in configuration
+		assert.False(t, cfg.check("Template").enabled())
+		assert.Equal(t, severityNotice, cfg.check("Template").severityFor(true))
+
+		// not listed in both files
+		assert.Equal(t, checkConfig{Severity: severityError}, (&config{}).check("Size"))
+		assert.True(t, (&config{}).check("Size").enabled())
+	})
+
+	cases := []struct {
+		name string
+		file string
+		err  string
+	}{{
+		name: "Empty",
+		file: "",
+		err:  "configuration is empty",
+	}, {
+		name: "NoVersion",
+		file: "checks: {}",
+		err:  "unsupported version 0, expected 1",
+	}, {
+		name: "UnknownField",
+		file: "version: 1\ntitle:\n  max_len: 50\n",
+		err:  "yaml: unmarshal errors:\n  line 3: field max_len not found in type conformpr.titleConfig",
+	}, {
+		name: "UnknownCheck",
+		file: "version: 1\nchecks:\n  milestone: {enabled: true}\n",
+		err:  `checks: unknown check "milestone", expected one of: labels, size, sprint, title, body, template, issue, auto-merge`,
+	}, {
+		name: "Severity",
+		file: "version: 1\nchecks:\n  title: {enabled: true, severity: fatal}\n",
+		err:  `checks.title: unexpected severity "fatal", expected one of: error, warning, notice`,
+	}, {
+		name: "BlockingLabel",
+		file: "version: 1\nlabels:\n  blocking:\n    - name: wip\n",
+		err:  "labels.blocking[0].message: must be set",
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := parseConfig([]byte(tc.file))
+			require.Error(t, err)
+			assert.Equal(t, tc.err, err.Error())
+		})
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "custom.yml"), []byte("version: 1\ntitle:\n  max_length: 50\n"), 0o666))
+
+	t.Run("Custom", func(t *testing.T) {
+		t.Parallel()
+
+		getenv := testutil.GetEnvFunc(t, map[string]string{
+			"GITHUB_WORKSPACE": dir,
+			"INPUT_CONFIG":     "custom.yml",
+		})
+
+		cfg, err := loadConfig(githubactions.New(githubactions.WithGetenv(getenv)))
+		require.NoError(t, err)
+		assert.Equal(t, 50, cfg.Title.MaxLength)
+
+		// the rest is merged from built-in configuration
+		expected := defaultConfig()
+		expected.Title.MaxLength = 50
+		assert.Equal(t, expected, cfg)
+		assert.True(t, cfg.Title.ImperativeVerb)
+		assert.Contains(t, cfg.Labels.Required, "code/bug")
+		assert.False(t, cfg.check("Template").enabled())
+		assert.False(t, cfg.check("Issue").enabled())
+	})
+
+	t.Run("CustomLists", func(t *testing.T) {
+		t.Parallel()
+
+		cfg, err := parseConfig([]byte("version: 1\nlabels:\n  required: [bug]\n"))
+		require.NoError(t, err)
+
+		// lists are replaced, other fields are kept
+		assert.Equal(t, []string{"bug"}, cfg.Labels.Required)
+		assert.Equal(t, defaultConfig().Labels.Blocking, cfg.Labels.Blocking)
+	})
+}
@@ -0,0 +1,153 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conformpr
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v70/github"
+	"github.com/sethvargo/go-githubactions"
+	"golang.org/x/exp/slices"
+
+	"github.com/FerretDB/github-actions/internal"
+)
+
+// conformCommand is a PR comment that re-runs checks.
+const conformCommand = "/conform"
+
+// commandAssociations contains author associations of users that could use conformCommand.
+//
+// See https://docs.github.com/en/graphql/reference/enums#commentauthorassociation.
+var commandAssociations = []string{"OWNER", "MEMBER", "COLLABORATOR"}
+
+// target is a PR to check.
+type target struct {
+	pr *github.PullRequest
+
+	// commit SHA for check runs; differs from PR head for merge groups
+	headSHA string
+}
+
+// resolveTargets returns PRs to check for the given event.
+//
+// Events other than `pull_request` and `pull_request_target` require the REST client
+// to fetch PRs. Empty result without error means that there is nothing to check.
+func resolveTargets(ctx context.Context, action *githubactions.Action, client *github.Client, event *internal.Event) ([]target, error) { //nolint:lll // for readability
+	if e, ok := event.Payload.(*github.PullRequestEvent); ok {
+		return []target{{pr: e.PullRequest, headSHA: event.HeadSHA()}}, nil
+	}
+
+	if client == nil {
+		return nil, fmt.Errorf("resolveTargets: GITHUB_TOKEN is required for %q event", event.Name)
+	}
+
+	owner, repo := event.Repo()
+	if owner == "" || repo == "" {
+		return nil, fmt.Errorf("resolveTargets: failed to determine repository")
+	}
+
+	var number int
+	var headSHA string
+
+	switch e := event.Payload.(type) {
+	case *internal.ScheduleEvent:
+		prs, err := listOpenPRs(ctx, client, owner, repo)
+		if err != nil {
+			return nil, fmt.Errorf("resolveTargets: %w", err)
+		}
+
+		res := make([]target, len(prs))
+		for i, pr := range prs {
+			res[i] = target{pr: pr, headSHA: pr.GetHead().GetSHA()}
+		}
+
+		return res, nil
+
+	case *github.MergeGroupEvent:
+		if number = event.PRNumber(); number == 0 {
+			return nil, fmt.Errorf("resolveTargets: unexpected merge group ref %q", e.GetMergeGroup().GetHeadRef())
+		}
+
+		headSHA = event.HeadSHA()
+
+	case *github.WorkflowDispatchEvent:
+		var err error
+		if number, err = strconv.Atoi(action.GetInput("pr-number")); err != nil {
+			return nil, fmt.Errorf("resolveTargets: invalid `pr-number` input: %w", err)
+		}
+
+	case *github.IssueCommentEvent:
+		number = event.PRNumber()
+		if number == 0 || e.GetAction() != "created" || !isConformCommand(e.GetComment().GetBody()) {
+			action.Infof("Comment is not a %s command for a PR, nothing to check.", conformCommand)
+			return nil, nil
+		}
+
+		if a := e.GetComment().GetAuthorAssociation(); !slices.Contains(commandAssociations, a) {
+			action.Infof("%s command from %q (%s) is ignored, nothing to check.", conformCommand, e.GetComment().GetUser().GetLogin(), a)
+			return nil, nil
+		}
+
+	default:
+		return nil, fmt.Errorf("resolveTargets: unexpected %q event", event.Name)
+	}
+
+	pr, _, err := client.PullRequests.Get(ctx, owner, repo, number)
+	if err != nil {
+		return nil, fmt.Errorf("resolveTargets: %w", err)
+	}
+
+	if headSHA == "" {
+		headSHA = pr.GetHead().GetSHA()
+	}
+
+	return []target{{pr: pr, headSHA: headSHA}}, nil
+}
+
+// isConformCommand returns true if the comment's first line is the conform command.
+func isConformCommand(body string) bool {
+	line, _, _ := strings.Cut(strings.TrimSpace(body), "\n")
+	return strings.TrimSpace(line) == conformCommand
+}
+
+// listOpenPRs returns all open PRs in owner/repo.
+func listOpenPRs(ctx context.Context, client *github.Client, owner, repo string) ([]*github.PullRequest, error) {
+	opts := &github.PullRequestListOptions{
+		State:       "open",
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+
+	var res []*github.PullRequest
+
+	for {
+		prs, resp, err := client.PullRequests.List(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("listOpenPRs: %w", err)
+		}
+
+		res = append(res, prs...)
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		opts.Page = resp.NextPage
+	}
+
+	return res, nil
+}
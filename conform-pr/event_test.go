@@ -0,0 +1,172 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conformpr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-github/v70/github"
+	"github.com/sethvargo/go-githubactions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/github-actions/internal"
+	"github.com/FerretDB/github-actions/internal/testutil"
+)
+
+func TestResolveTargets(t *testing.T) {
+	t.Parallel()
+
+	pr := func(number int) *github.PullRequest {
+		return &github.PullRequest{
+			Number: github.Ptr(number),
+			NodeID: github.Ptr(fmt.Sprintf("PR_%d", number)),
+			Head:   &github.PullRequestBranch{SHA: github.Ptr(fmt.Sprintf("head%d", number))},
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/FerretDB/FerretDB/pulls/42", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(pr(42))
+	})
+	mux.HandleFunc("/repos/FerretDB/FerretDB/pulls", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "open", r.URL.Query().Get("state"))
+
+		if r.URL.Query().Get("page") == "" {
+			w.Header().Set("Link", fmt.Sprintf(`<%s%s?page=2>; rel="next"`, "http://"+r.Host, r.URL.Path))
+			json.NewEncoder(w).Encode([]*github.PullRequest{pr(1), pr(2)})
+			return
+		}
+
+		json.NewEncoder(w).Encode([]*github.PullRequest{pr(3)})
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(srv.URL + "/")
+
+	cases := []struct {
+		name      string
+		eventName string
+		event     any
+		prNumber  string
+		expected  []target
+	}{{
+		name:      "PullRequest",
+		eventName: "pull_request_target",
+		event:     &github.PullRequestEvent{PullRequest: pr(7)},
+		expected:  []target{{pr: pr(7), headSHA: "head7"}},
+	}, {
+		name:      "MergeGroup",
+		eventName: "merge_group",
+		event: &github.MergeGroupEvent{MergeGroup: &github.MergeGroup{
+			HeadRef: github.Ptr("refs/heads/gh-readonly-queue/main/pr-42-f0e6a1b2c3d4"),
+			HeadSHA: github.Ptr("queue42"),
+		}},
+		expected: []target{{pr: pr(42), headSHA: "queue42"}},
+	}, {
+		name:      "WorkflowDispatch",
+		eventName: "workflow_dispatch",
+		event:     &github.WorkflowDispatchEvent{},
+		prNumber:  "42",
+		expected:  []target{{pr: pr(42), headSHA: "head42"}},
+	}, {
+		name:      "IssueComment",
+		eventName: "issue_comment",
+		event: &github.IssueCommentEvent{
+			Action: github.Ptr("created"),
+			Issue: &github.Issue{
+				Number:           github.Ptr(42),
+				PullRequestLinks: &github.PullRequestLinks{},
+			},
+			Comment: &github.IssueComment{
+				Body:              github.Ptr(" /conform\r\nPlease."),
+				AuthorAssociation: github.Ptr("MEMBER"),
+			},
+		},
+		expected: []target{{pr: pr(42), headSHA: "head42"}},
+	}, {
+		name:      "IssueCommentUntrusted",
+		eventName: "issue_comment",
+		event: &github.IssueCommentEvent{
+			Action: github.Ptr("created"),
+			Issue: &github.Issue{
+				Number:           github.Ptr(42),
+				PullRequestLinks: &github.PullRequestLinks{},
+			},
+			Comment: &github.IssueComment{
+				Body:              github.Ptr("/conform"),
+				AuthorAssociation: github.Ptr("NONE"),
+				User:              &github.User{Login: github.Ptr("drive-by")},
+			},
+		},
+	}, {
+		name:      "IssueCommentOther",
+		eventName: "issue_comment",
+		event: &github.IssueCommentEvent{
+			Action: github.Ptr("created"),
+			Issue: &github.Issue{
+				Number:           github.Ptr(42),
+				PullRequestLinks: &github.PullRequestLinks{},
+			},
+			Comment: &github.IssueComment{Body: github.Ptr("Please /conform")},
+		},
+	}, {
+		name:      "IssueCommentNotPR",
+		eventName: "issue_comment",
+		event: &github.IssueCommentEvent{
+			Action:  github.Ptr("created"),
+			Issue:   &github.Issue{Number: github.Ptr(42)},
+			Comment: &github.IssueComment{Body: github.Ptr("/conform")},
+		},
+	}, {
+		name:      "Schedule",
+		eventName: "schedule",
+		event:     &internal.ScheduleEvent{},
+		expected: []target{
+			{pr: pr(1), headSHA: "head1"},
+			{pr: pr(2), headSHA: "head2"},
+			{pr: pr(3), headSHA: "head3"},
+		},
+	}}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			getenv := testutil.GetEnvFunc(t, map[string]string{
+				"GITHUB_REPOSITORY": "FerretDB/FerretDB",
+				"GITHUB_SHA":        "",
+				"GITHUB_ACTOR":      "",
+				"INPUT_PR-NUMBER":   tc.prNumber,
+			})
+			action := githubactions.New(githubactions.WithGetenv(getenv))
+
+			event := internal.NewEvent(action, tc.eventName, tc.event)
+			actual, err := resolveTargets(context.Background(), action, client, event)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conformpr
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// fixResult is a result of a single automatic fix.
+type fixResult struct {
+	check       string
+	description string
+	err         error
+}
+
+// canFix returns true if automatic fixes can be applied to the report's PR.
+//
+// They are applied only to PRs from maintainers,
+// or when the workflow was manually run by a maintainer.
+func (c *checker) canFix(ctx context.Context, owner, repo string, rep *report) bool {
+	if rep.pr == nil {
+		return false
+	}
+
+	if !rep.community {
+		return true
+	}
+
+	if c.action.Getenv("GITHUB_EVENT_NAME") != "workflow_dispatch" {
+		return false
+	}
+
+	actor := c.action.Getenv("GITHUB_ACTOR")
+	maintainer, source := c.resolveMaintainer(ctx, owner, repo, actor)
+	c.action.Infof("Workflow actor @%s is a maintainer: %t (according to %s).", actor, maintainer, source)
+
+	return maintainer
+}
+
+// applyFixes applies safe automatic fixes for failed checks of the report's PR.
+//
+// Checks that are not listed in the result still need a human.
+func (c *checker) applyFixes(ctx context.Context, nodeID string, rep *report) []fixResult {
+	failed := make(map[string]bool)
+	for _, res := range rep.results {
+		if res.err != nil {
+			failed[res.check] = true
+		}
+	}
+
+	var res []fixResult
+
+	pr := rep.pr
+	title, body := pr.Title, pr.Body
+
+	if failed["Title"] {
+		if fixed := fixTitle(title); fixed != title && checkTitle(c.action, &c.config.Title, fixed) == nil {
+			res = append(res, fixResult{
+				check:       "Title",
+				description: fmt.Sprintf("Changed title from %q to %q.", title, fixed),
+			})
+			title = fixed
+		}
+	}
+
+	if failed["Body"] {
+		if fixed := fixBody(body); fixed != body && checkBody(c.action, &c.config.Body, fixed) == nil {
+			res = append(res, fixResult{
+				check:       "Body",
+				description: "Added a final punctuation mark to the body.",
+			})
+			body = fixed
+		}
+	}
+
+	if title != pr.Title || body != pr.Body {
+		err := c.gClient.UpdatePullRequest(ctx, nodeID, title, body)
+		for i := range res {
+			res[i].err = err
+		}
+	}
+
+	if failed["Auto-merge"] {
+		res = append(res, fixResult{
+			check:       "Auto-merge",
+			description: "Enabled auto-merge.",
+			err:         c.gClient.EnableAutoMerge(ctx, nodeID),
+		})
+	}
+
+	if failed["Size"] {
+		projects, err := c.gClient.ClearProjectField(ctx, nodeID, "Size")
+		res = append(res, fixResult{
+			check:       "Size",
+			description: fmt.Sprintf(`Cleared "Size" field for projects %q.`, projects),
+			err:         err,
+		})
+	}
+
+	return res
+}
+
+// fixesSummary returns applied fixes in Markdown.
+func fixesSummary(fixes []fixResult) string {
+	if len(fixes) == 0 {
+		return ""
+	}
+
+	var buf strings.Builder
+	buf.WriteString("\nAutomatic fixes:\n\n")
+
+	for _, f := range fixes {
+		if f.err != nil {
+			fmt.Fprintf(&buf, "* ❌ %s: %s Failed: %s.\n", f.check, f.description, f.err)
+			continue
+		}
+
+		fmt.Fprintf(&buf, "* ✅ %s: %s\n", f.check, f.description)
+	}
+
+	return buf.String()
+}
+
+// fixTitle removes a prefix like `feat:` and trailing punctuation from the title,
+// and capitalizes the first letter.
+func fixTitle(title string) string {
+	title = strings.TrimSpace(title)
+
+	if firstWord, rest, ok := strings.Cut(title, " "); ok && strings.HasSuffix(firstWord, ":") {
+		title = strings.TrimSpace(rest)
+	}
+
+	title = strings.TrimRight(title, " .,;:!?")
+
+	return capitalize(title)
+}
+
+// noDotLineRe matches last lines of PR bodies that should not end with a dot:
+// closing code fences, list items, headings, and URLs.
+var noDotLineRe = regexp.MustCompile("^(`{3}|~{3}|#{1,6}(\\s|$)|([-*+]|\\d+[.)])\\s)|https?://\\S+$")
+
+// fixBody removes trailing whitespace from the body and adds a dot to the end
+// if it does not end with a punctuation mark.
+//
+// Bodies ending with Markdown constructs that would be broken by a dot are returned as is.
+func fixBody(body string) string {
+	trimmed := strings.TrimRight(body, " \t\r\n")
+	if trimmed == "" {
+		return body
+	}
+
+	lastLine := trimmed
+	if i := strings.LastIndex(trimmed, "\n"); i >= 0 {
+		lastLine = trimmed[i+1:]
+	}
+
+	if noDotLineRe.MatchString(strings.TrimSpace(lastLine)) {
+		return body
+	}
+
+	if strings.ContainsAny(trimmed[len(trimmed)-1:], ".!?") {
+		return trimmed
+	}
+
+	return trimmed + "."
+}
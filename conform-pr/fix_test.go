@@ -0,0 +1,82 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conformpr
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFixTitle(t *testing.T) {
+	cases := []struct {
+		title    string
+		expected string
+	}{
+		{title: "Fix a bug.", expected: "Fix a bug"},
+		{title: "feat: add `$sort` support", expected: "Add `$sort` support"},
+		{title: "fix(ci): bump Go version!  ", expected: "Bump Go version"},
+		{title: "Fix a bug", expected: "Fix a bug"},
+		{title: "...", expected: ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.title, func(t *testing.T) {
+			assert.Equal(t, tc.expected, fixTitle(tc.title))
+		})
+	}
+}
+
+func TestFixBody(t *testing.T) {
+	cases := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{name: "Empty", body: "", expected: ""},
+		{name: "NoDot", body: "Closes #1\r\n\r\n", expected: "Closes #1."},
+		{name: "Dot", body: "Closes #1.\r\n", expected: "Closes #1."},
+		{name: "Question", body: "Why?", expected: "Why?"},
+		{name: "Fence", body: "Output:\r\n\r\n```\r\nok\r\n```\r\n", expected: "Output:\r\n\r\n```\r\nok\r\n```\r\n"},
+		{name: "TildeFence", body: "Output:\n~~~\nok\n~~~", expected: "Output:\n~~~\nok\n~~~"},
+		{name: "List", body: "Changes:\n\n* first\n* second", expected: "Changes:\n\n* first\n* second"},
+		{name: "NumberedList", body: "Steps:\n\n1. first\n2) second", expected: "Steps:\n\n1. first\n2) second"},
+		{name: "NestedList", body: "Changes:\n\n- first\n  - nested", expected: "Changes:\n\n- first\n  - nested"},
+		{name: "URL", body: "See https://example.com/docs", expected: "See https://example.com/docs"},
+		{name: "Heading", body: "Text.\n\n## Notes", expected: "Text.\n\n## Notes"},
+		{name: "NotHeading", body: "Closes #1", expected: "Closes #1."},
+		{name: "NotList", body: "Closes 1", expected: "Closes 1."},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, fixBody(tc.body))
+		})
+	}
+}
+
+func TestFixesSummary(t *testing.T) {
+	assert.Empty(t, fixesSummary(nil))
+
+	fixes := []fixResult{
+		{check: "Title", description: `Changed title from "Fix." to "Fix".`},
+		{check: "Auto-merge", description: "Enabled auto-merge.", err: errors.New("not allowed")},
+	}
+	expected := "\nAutomatic fixes:\n\n" +
+		"* ✅ Title: Changed title from \"Fix.\" to \"Fix\".\n" +
+		"* ❌ Auto-merge: Enabled auto-merge. Failed: not allowed.\n"
+	assert.Equal(t, expected, fixesSummary(fixes))
+}
@@ -0,0 +1,83 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conformpr
+
+import (
+	"fmt"
+
+	"github.com/sethvargo/go-githubactions"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+
+	"github.com/FerretDB/github-actions/internal/graphql"
+)
+
+// checkLinkedIssues checks that PR closes at least one open issue,
+// and that linked issues are in the same projects and sprints as PR itself.
+//
+// PR's "Size" field should be unset (see checkSize) because the size is estimated for the issue,
+// so the linked issue should have it set instead.
+//
+// Every violation is returned as a separate error.
+func checkLinkedIssues(_ *githubactions.Action, cfg *issueConfig, pr *graphql.PullRequest, issues []graphql.Issue) []error {
+	if pr.Closed {
+		return nil
+	}
+
+	for _, label := range pr.Labels {
+		if slices.Contains(cfg.ExemptLabels, label) {
+			return nil
+		}
+	}
+
+	if len(issues) == 0 {
+		return []error{fmt.Errorf("PR must close an issue; add `Closes #123` to the body or link it manually.")}
+	}
+
+	// sort projects to make results stable
+	projects := maps.Keys(pr.ProjectFields)
+	slices.Sort(projects)
+
+	var res []error
+
+	for _, issue := range issues {
+		name := fmt.Sprintf("%s#%d", issue.Repository, issue.Number)
+
+		if issue.Closed {
+			res = append(res, fmt.Errorf("Linked issue %s must be open.", name))
+		}
+
+		for _, project := range projects {
+			fields, ok := issue.ProjectFields[project]
+			if !ok {
+				res = append(res, fmt.Errorf("Linked issue %s must be in project %q.", name, project))
+				continue
+			}
+
+			if sprint := pr.ProjectFields[project]["Sprint"]; sprint != "" && fields["Sprint"] != sprint {
+				res = append(res, fmt.Errorf(
+					`Linked issue %s must have "Sprint" field %q for project %q, got %q.`,
+					name, sprint, project, fields["Sprint"],
+				))
+			}
+
+			if fields["Size"] == "" {
+				res = append(res, fmt.Errorf(`Linked issue %s should have "Size" field set for project %q.`, name, project))
+			}
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,119 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conformpr
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sethvargo/go-githubactions"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/FerretDB/github-actions/internal/graphql"
+)
+
+func TestCheckLinkedIssues(t *testing.T) {
+	t.Parallel()
+
+	cfg := &defaultConfig().Issue
+
+	projectFields := map[string]graphql.Fields{
+		"FerretDB": {"Sprint": "Sprint-2023-42", "Size": ""},
+	}
+
+	cases := []struct {
+		name     string
+		pr       *graphql.PullRequest
+		issues   []graphql.Issue
+		expected []error
+	}{{
+		name: "Valid",
+		pr: &graphql.PullRequest{
+			ProjectFields: projectFields,
+		},
+		issues: []graphql.Issue{{
+			Number:     123,
+			Repository: "FerretDB/FerretDB",
+			ProjectFields: map[string]graphql.Fields{
+				"FerretDB": {"Sprint": "Sprint-2023-42", "Size": "S"},
+				"Other":    {"Sprint": ""},
+			},
+		}},
+	}, {
+		name: "NoIssue",
+		pr: &graphql.PullRequest{
+			Labels:        []string{"code/bug"},
+			ProjectFields: projectFields,
+		},
+		expected: []error{
+			errors.New("PR must close an issue; add `Closes #123` to the body or link it manually."),
+		},
+	}, {
+		name: "ExemptLabel",
+		pr: &graphql.PullRequest{
+			Labels:        []string{"deps"},
+			ProjectFields: projectFields,
+		},
+	}, {
+		name: "ClosedPR",
+		pr: &graphql.PullRequest{
+			Closed:        true,
+			ProjectFields: projectFields,
+		},
+	}, {
+		name: "Mismatch",
+		pr: &graphql.PullRequest{
+			ProjectFields: map[string]graphql.Fields{
+				"FerretDB": {"Sprint": "Sprint-2023-42"},
+				"Docs":     {"Sprint": ""},
+			},
+		},
+		issues: []graphql.Issue{{
+			Number:     1,
+			Repository: "FerretDB/FerretDB",
+			Closed:     true,
+			ProjectFields: map[string]graphql.Fields{
+				"FerretDB": {"Sprint": "Sprint-2023-41", "Size": "M"},
+			},
+		}, {
+			Number:     2,
+			Repository: "FerretDB/dance",
+			ProjectFields: map[string]graphql.Fields{
+				"Docs":     {"Size": "S"},
+				"FerretDB": {"Sprint": "Sprint-2023-42"},
+			},
+		}},
+		expected: []error{
+			errors.New("Linked issue FerretDB/FerretDB#1 must be open."),
+			errors.New(`Linked issue FerretDB/FerretDB#1 must be in project "Docs".`),
+			errors.New(
+				`Linked issue FerretDB/FerretDB#1 must have "Sprint" field "Sprint-2023-42" ` +
+					`for project "FerretDB", got "Sprint-2023-41".`,
+			),
+			errors.New(`Linked issue FerretDB/dance#2 should have "Size" field set for project "FerretDB".`),
+		},
+	}}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			action := githubactions.New()
+			actual := checkLinkedIssues(action, cfg, tc.pr, tc.issues)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
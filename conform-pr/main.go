@@ -12,202 +12,549 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package main
+// Package conformpr implements `conform-pr` action.
+package conformpr
 
 import (
 	"context"
 	"encoding/hex"
 	"errors"
-	"flag"
 	"fmt"
+	"html"
 	"regexp"
 	"strings"
-	"text/tabwriter"
 	"unicode/utf8"
 
 	"github.com/google/go-github/v70/github"
-	"github.com/jdkato/prose/v2"
 	"github.com/sethvargo/go-githubactions"
 	"golang.org/x/exp/maps"
 	"golang.org/x/exp/slices"
 
 	"github.com/FerretDB/github-actions/internal"
 	"github.com/FerretDB/github-actions/internal/graphql"
+	"github.com/FerretDB/github-actions/internal/output"
 )
 
-func main() {
-	flag.Parse()
-
-	ctx := context.Background()
-	action := githubactions.New()
-	gClient := graphql.NewClient(ctx, action, "CONFORM_TOKEN")
+// Run runs the action.
+func Run(ctx context.Context, action *githubactions.Action) error {
+	gClient, err := graphql.NewClient(ctx, action, "CONFORM_TOKEN")
+	if err != nil {
+		return fmt.Errorf("failed to create GraphQL client: %w", err)
+	}
 
 	internal.DebugEnv(action)
 
+	cfg, err := loadConfig(action)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
 	event, err := internal.ReadEvent(action)
 	if err != nil {
-		action.Errorf("Failed to read event: %s.", err)
+		return fmt.Errorf("failed to read event: %w", err)
 	}
 
-	prEvent, ok := event.(*github.PullRequestEvent)
-	if !ok {
-		action.Fatalf("Unexpected event type: %T.", event)
+	// used for fetching PRs, comments, and check runs
+	var client *github.Client
+	if action.Getenv("GITHUB_TOKEN") != "" {
+		if client, err = internal.GitHubClient(ctx, action, "GITHUB_TOKEN"); err != nil {
+			return fmt.Errorf("failed to create GitHub client: %w", err)
+		}
+	}
+
+	targets, err := resolveTargets(ctx, action, client, event)
+	if err != nil {
+		return fmt.Errorf("failed to resolve PRs to check: %w", err)
 	}
 
 	c := &checker{
 		action:  action,
 		gClient: gClient,
+		config:  cfg,
 	}
 
-	results, community := c.runChecks(
-		ctx,
-		*prEvent.Organization.Login, *prEvent.PullRequest.User.Login, *prEvent.PullRequest.NodeID,
-	)
+	if client != nil && action.GetInput("comment") != "false" {
+		if c.login, err = internal.TokenLogin(ctx, action, client); err != nil {
+			action.Warningf("Failed to get token's login, not updating PR comments: %s.", err)
+		}
+	}
 
-	var buf strings.Builder
-	w := tabwriter.NewWriter(&buf, 1, 1, 1, ' ', tabwriter.Debug)
-	fmt.Fprintf(w, "\tCheck\tStatus\t\n")
-	fmt.Fprintf(w, "\t-----\t------\t\n")
+	// during sweeps, check all PRs even if some of them fail, and fail once at the end
+	var checked []target
+	var reports []*report
+	var failedToCheck int
+
+	for _, t := range targets {
+		rep, err := c.conformPR(ctx, client, t, len(targets) > 1)
+		if err != nil {
+			action.Errorf("Failed to check PR #%d: %s.", t.pr.GetNumber(), err)
+			failedToCheck++
+
+			continue
+		}
+
+		checked = append(checked, t)
+		reports = append(reports, rep)
+	}
+
+	internal.AddRateLimitSummary(action)
+
+	result := newResultJSON(checked, reports)
+	result.Conform = result.Conform && failedToCheck == 0
+
+	if err = output.SetResult(action, result); err != nil {
+		return fmt.Errorf("failed to set result: %w", err)
+	}
+
+	if failedToCheck > 0 {
+		return fmt.Errorf("failed to check %d of %d PR(s), see errors above", failedToCheck, len(targets))
+	}
 
-	conform := true
-	for _, res := range results {
+	// Check runs carry the status, and branch protection should require them,
+	// so the job does not fail for PRs with published check runs.
+	// If they were not published (for example, for PRs from forks), the job fails as usual.
+	var unpublished []*report
+	for _, rep := range reports {
+		if !rep.checkRunsCreated {
+			unpublished = append(unpublished, rep)
+		}
+	}
+
+	if len(unpublished) < len(reports) {
+		action.Infof("Results of %d PR(s) were published as check runs; the job fails only for others.", len(reports)-len(unpublished))
+	}
+
+	reports = unpublished
+
+	if len(reports) == 1 {
+		if rep := reports[0]; !rep.conform() {
+			if rep.community {
+				return errors.New("maintainers will update that PR to conform to the project's standards")
+			}
+
+			return errors.New("PR does not conform to the project's standards")
+		}
+
+		return nil
+	}
+
+	var failed int
+	for _, rep := range reports {
+		if !rep.conform() {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d PRs do not conform to the project's standards", failed, len(reports))
+	}
+
+	return nil
+}
+
+// conformPR checks a single PR, applies fixes, and reports results.
+//
+// If sweep is true, several PRs are checked, so the step summary has a heading for each of them.
+func (c *checker) conformPR(ctx context.Context, client *github.Client, t target, sweep bool) (*report, error) {
+	action := c.action
+
+	owner, repo := t.pr.GetBase().GetRepo().GetOwner().GetLogin(), t.pr.GetBase().GetRepo().GetName()
+	user, nodeID := t.pr.GetUser().GetLogin(), t.pr.GetNodeID()
+
+	rep, err := c.runChecks(ctx, owner, repo, user, nodeID)
+	if err != nil {
+		return nil, fmt.Errorf("conformPR: %w", err)
+	}
+
+	var fixes []fixResult
+
+	if action.GetInput("fix") == "true" && !rep.conform() {
+		if c.canFix(ctx, owner, repo, rep) {
+			fixes = c.applyFixes(ctx, nodeID, rep)
+
+			// check again to report what still needs a human
+			if len(fixes) > 0 {
+				if rep, err = c.runChecks(ctx, owner, repo, user, nodeID); err != nil {
+					return nil, fmt.Errorf("conformPR: %w", err)
+				}
+			}
+
+			rep.fixes = fixes
+		} else {
+			action.Infof("Automatic fixes are applied only to PRs from maintainers or when run by a maintainer.")
+		}
+	}
+
+	summary := rep.summary() + fixesSummary(fixes)
+	if sweep {
+		// expand only PRs that need attention
 		status := "✅"
-		if res.err != nil {
-			status = "❌ " + res.err.Error()
-			conform = false
+		if !rep.conform() {
+			status = "❌"
 		}
 
-		fmt.Fprintf(w, "\t%s\t%s\t\n", res.check, status)
+		title := fmt.Sprintf("%s #%d %s", status, t.pr.GetNumber(), html.EscapeString(t.pr.GetTitle()))
+		output.AddSummary(action, output.Details(title, summary, !rep.conform()))
+	} else {
+		output.AddSummary(action, summary)
 	}
+	rep.annotate(action)
 
-	w.Flush()
-	action.AddStepSummary(buf.String())
-	action.Infof("%s", buf.String())
+	conform := rep.conform()
 
-	if !conform {
-		if community {
-			action.Fatalf("Maintainers will update that PR to conform to the project's standards.")
+	if action.GetInput("comment") != "false" {
+		postComment(ctx, action, client, c.login, t.pr, summary, conform)
+	}
+
+	if action.GetInput("check-runs") == "true" {
+		if client == nil {
+			return nil, errors.New("conformPR: GITHUB_TOKEN is required for check runs")
 		}
 
-		action.Fatalf("PR does not conform to the project's standards.")
+		err = createCheckRuns(ctx, action, client, owner, repo, t.headSHA, rep.checkRuns())
+		switch {
+		case err == nil:
+			rep.checkRunsCreated = true
+		case internal.IsForbidden(err):
+			action.Warningf(
+				"Failed to create check runs (%s), probably because of read-only token; "+
+					"results are reported in the log and job summary.", err,
+			)
+		default:
+			return nil, fmt.Errorf("conformPR: %w", err)
+		}
 	}
+
+	return rep, nil
 }
 
 // checker holds state shared by all checks.
 type checker struct {
 	action  *githubactions.Action
 	gClient *graphql.Client
+	config  *config
+	login   string // GITHUB_TOKEN's user or bot that writes PR comments
 }
 
 // checkResult is a result of a single check.
 type checkResult struct {
-	check string
-	err   error
+	check    string
+	err      error
+	severity severity // set only for failed checks
 }
 
-// runChecks runs all the checks for the given PR.
-//
-// It returns check results and a flag indicating if the PR is from the community (true if yet).
-func (c *checker) runChecks(ctx context.Context, org, user, nodeID string) ([]checkResult, bool) {
+// report contains results of all checks for a single PR.
+type report struct {
+	results []checkResult
+
+	// checked PR; nil if checks were skipped
+	pr *graphql.PullRequest
+
+	// PR author
+	user string
+
+	// true if PR is from the community (not from maintainers)
+	community bool
+
+	// describes how maintainer status was resolved; empty if it was not
+	maintainerSource string
+
+	// applied automatic fixes
+	fixes []fixResult
+
+	// true if results were published as check runs
+	checkRunsCreated bool
+}
+
+// conform returns true if there are no failed checks with error severity.
+func (r *report) conform() bool {
+	for _, res := range r.results {
+		if res.err != nil && res.severity == severityError {
+			return false
+		}
+	}
+
+	return true
+}
+
+// annotate logs failed checks as error, warning, or notice annotations.
+func (r *report) annotate(action *githubactions.Action) {
+	for _, res := range r.results {
+		if res.err == nil {
+			continue
+		}
+
+		a := output.Annotation{
+			Title:   res.check,
+			Message: res.err.Error(),
+		}
+
+		switch res.severity {
+		case severityError:
+			a.Level = output.Error
+		case severityWarning:
+			a.Level = output.Warning
+		case severityNotice:
+			a.Level = output.Notice
+		}
+
+		output.Annotate(action, a)
+	}
+}
+
+// summary returns check results table and maintainer status in Markdown.
+func (r *report) summary() string {
+	table := output.NewTable("Check", "Status")
+
+	for _, res := range r.results {
+		status := "✅"
+		if res.err != nil {
+			status = res.severity.mark() + " " + res.err.Error()
+		}
+
+		table.Add(res.check, status)
+	}
+
+	var buf strings.Builder
+	buf.WriteString(table.String())
+
+	if r.maintainerSource != "" {
+		status := "a maintainer"
+		if r.community {
+			status = "not a maintainer"
+		}
+
+		fmt.Fprintf(&buf, "\n@%s is %s according to %s.\n", r.user, status, r.maintainerSource)
+	}
+
+	return buf.String()
+}
+
+// resultJSON contains results for all checked PRs encoded as `json` output.
+type resultJSON struct {
+	Conform bool            `json:"conform"`
+	PRs     []*prResultJSON `json:"prs"`
+}
+
+// prResultJSON contains results for a single PR.
+type prResultJSON struct {
+	Number    int                `json:"number"`
+	URL       string             `json:"url"`
+	Author    string             `json:"author"`
+	Conform   bool               `json:"conform"`
+	Community bool               `json:"community"`
+	Checks    []*checkResultJSON `json:"checks"`
+	Fixes     []*fixResultJSON   `json:"fixes,omitempty"`
+}
+
+// checkResultJSON contains a result of a single check.
+type checkResultJSON struct {
+	Check    string   `json:"check"`
+	Passed   bool     `json:"passed"`
+	Severity severity `json:"severity,omitempty"`
+	Message  string   `json:"message,omitempty"`
+}
+
+// fixResultJSON contains a result of a single automatic fix.
+type fixResultJSON struct {
+	Check       string `json:"check"`
+	Description string `json:"description"`
+	Error       string `json:"error,omitempty"`
+}
+
+// newResultJSON returns results for the given targets and their reports.
+func newResultJSON(targets []target, reports []*report) *resultJSON {
+	res := &resultJSON{
+		Conform: true,
+		PRs:     make([]*prResultJSON, 0, len(reports)),
+	}
+
+	for i, rep := range reports {
+		pr := rep.json(targets[i].pr)
+		res.Conform = res.Conform && pr.Conform
+		res.PRs = append(res.PRs, pr)
+	}
+
+	return res
+}
+
+// json returns results of the given PR for `json` output.
+func (r *report) json(pr *github.PullRequest) *prResultJSON {
+	res := &prResultJSON{
+		Number:    pr.GetNumber(),
+		URL:       pr.GetHTMLURL(),
+		Author:    r.user,
+		Conform:   r.conform(),
+		Community: r.community,
+		Checks:    make([]*checkResultJSON, 0, len(r.results)),
+	}
+
+	for _, cr := range r.results {
+		c := &checkResultJSON{
+			Check:  cr.check,
+			Passed: cr.err == nil,
+		}
+
+		if cr.err != nil {
+			c.Severity = cr.severity
+			c.Message = cr.err.Error()
+		}
+
+		res.Checks = append(res.Checks, c)
+	}
+
+	for _, fr := range r.fixes {
+		f := &fixResultJSON{
+			Check:       fr.check,
+			Description: fr.description,
+		}
+
+		if fr.err != nil {
+			f.Error = fr.err.Error()
+		}
+
+		res.Fixes = append(res.Fixes, f)
+	}
+
+	return res
+}
+
+// runChecks runs all the checks for the given PR in the owner/repo repository.
+func (c *checker) runChecks(ctx context.Context, owner, repo, user, nodeID string) (*report, error) {
+	rep := &report{
+		user: user,
+	}
+
 	// Do less API calls and be nice to dependabot's compatibility scoring feature:
 	// https://docs.github.com/en/code-security/dependabot/dependabot-security-updates/about-dependabot-security-updates#about-compatibility-scores
 	//nolint:lll // that URL is long
 	if user == "dependabot[bot]" {
-		return nil, false
+		return rep, nil
 	}
 
-	_, maintainer := maintainers[user]
+	maintainer, source := c.resolveMaintainer(ctx, owner, repo, user)
 	community := !maintainer
+	rep.community = community
+	rep.maintainerSource = source
+
+	pr, err := c.gClient.GetPullRequest(ctx, nodeID)
+	if err != nil {
+		return nil, fmt.Errorf("runChecks: %w", err)
+	}
 
-	pr := c.gClient.GetPullRequest(ctx, nodeID)
+	rep.pr = pr
 
 	var res []checkResult
-	for _, err := range checkLabels(c.action, pr.Labels) {
-		res = append(res, checkResult{
-			check: "Labels",
-			err:   err,
-		})
-	}
 
-	if res == nil {
-		res = append(res, checkResult{
-			check: "Labels",
-		})
+	if c.config.check("Labels").enabled() {
+		res = append(res, multiResults("Labels", checkLabels(c.action, &c.config.Labels, pr.Labels))...)
 	}
 
-	res = append(res, checkResult{
+	for _, r := range []checkResult{{
 		check: "Size",
 		err:   checkSize(c.action, pr.ProjectFields),
-	})
-	res = append(res, checkResult{
+	}, {
 		check: "Sprint",
 		err:   checkSprint(c.action, pr.ProjectFields, community),
-	})
-	res = append(res, checkResult{
+	}, {
 		check: "Title",
-		err:   checkTitle(c.action, pr.Title),
-	})
-	res = append(res, checkResult{
+		err:   checkTitle(c.action, &c.config.Title, pr.Title),
+	}, {
 		check: "Body",
-		err:   checkBody(c.action, pr.Body),
-	})
-	res = append(res, checkResult{
-		check: "Auto-merge",
-		err:   checkAutoMerge(c.action, pr, community),
-	})
-
-	return res, community
+		err:   checkBody(c.action, &c.config.Body, pr.Body),
+	}} {
+		if c.config.check(r.check).enabled() {
+			res = append(res, r)
+		}
+	}
+
+	if c.config.check("Template").enabled() {
+		res = append(res, multiResults("Template", checkBodyTemplate(c.action, &c.config.Template, pr.Body))...)
+	}
+
+	if c.config.check("Issue").enabled() {
+		// linked issues are expensive to query, so they are fetched only for that check
+		issues, err := c.gClient.GetLinkedIssues(ctx, nodeID)
+		if err != nil {
+			return nil, fmt.Errorf("runChecks: %w", err)
+		}
+
+		res = append(res, multiResults("Issue", checkLinkedIssues(c.action, &c.config.Issue, pr, issues))...)
+	}
+
+	if c.config.check("Auto-merge").enabled() {
+		res = append(res, checkResult{
+			check: "Auto-merge",
+			err:   checkAutoMerge(c.action, pr, community),
+		})
+	}
+
+	for i := range res {
+		if res[i].err != nil {
+			res[i].severity = c.config.check(res[i].check).severityFor(community)
+		}
+	}
+
+	rep.results = res
+
+	return rep, nil
 }
 
-// checkLabels checks if PR's labels are valid.
-func checkLabels(_ *githubactions.Action, labels []string) []error {
-	var res []error
+// multiResults returns a result for every error of the check,
+// or a single successful result if there are no errors.
+func multiResults(check string, errs []error) []checkResult {
+	if len(errs) == 0 {
+		return []checkResult{{check: check}}
+	}
 
-	if slices.Contains(labels, "do not merge") {
-		res = append(res, fmt.Errorf("That PR should not be merged yet."))
+	res := make([]checkResult, len(errs))
+	for i, err := range errs {
+		res[i] = checkResult{
+			check: check,
+			err:   err,
+		}
 	}
 
-	if slices.Contains(labels, "not ready") {
-		res = append(res, fmt.Errorf("That PR can't be merged yet; remove `not ready` label."))
+	return res
+}
+
+// checkLabels checks if PR's labels are valid.
+func checkLabels(_ *githubactions.Action, cfg *labelsConfig, labels []string) []error {
+	var res []error
+
+	for _, l := range cfg.Blocking {
+		if slices.Contains(labels, l.Name) {
+			res = append(res, errors.New(l.Message))
+		}
 	}
 
 	var incorrect []string
 
 	for _, l := range labels {
-		switch {
-		case l == "badly estimated":
-		case l == "good first issue":
-		case l == "help wanted":
-		case l == "scope changed":
-		case strings.HasPrefix(l, "area/"):
-		case strings.HasPrefix(l, "backend/"):
+		forbidden := slices.Contains(cfg.Forbidden, l)
 
-		default:
-			continue
+		for _, prefix := range cfg.ForbiddenPrefixes {
+			if strings.HasPrefix(l, prefix) {
+				forbidden = true
+			}
 		}
 
-		incorrect = append(incorrect, l)
+		if forbidden {
+			incorrect = append(incorrect, l)
+		}
 	}
 
 	if incorrect != nil {
 		res = append(res, fmt.Errorf("Those labels should not be applied to PRs: %s.", strings.Join(incorrect, ", ")))
 	}
 
+	if len(cfg.Required) == 0 {
+		return res
+	}
+
 	var found bool
 
-	required := []string{
-		"blog/engineering",
-		"blog/marketing",
-		"code/bug",
-		"code/bug-regression",
-		"code/chore",
-		"code/enhancement",
-		"code/feature",
-		"deps",
-		"documentation",
-		"project",
-	}
-	for _, l := range required {
+	for _, l := range cfg.Required {
 		if slices.Contains(labels, l) {
 			found = true
 		}
@@ -216,7 +563,7 @@ func checkLabels(_ *githubactions.Action, labels []string) []error {
 	if !found {
 		res = append(res, fmt.Errorf(
 			"PR must have at least one of those labels:<br />%s.",
-			strings.Join(required, ", "),
+			strings.Join(cfg.Required, ", "),
 		))
 	}
 
@@ -264,7 +611,13 @@ func checkSprint(_ *githubactions.Action, projectFields map[string]graphql.Field
 }
 
 // checkTitle checks PR's title.
-func checkTitle(_ *githubactions.Action, title string) error {
+func checkTitle(_ *githubactions.Action, cfg *titleConfig, title string) error {
+	firstWord, _, _ := strings.Cut(title, " ")
+
+	if cfg.ImperativeVerb && strings.HasPrefix(firstWord, "`") {
+		return checkTitleVerb(cfg, firstWord)
+	}
+
 	uppercaseRegexp := regexp.MustCompile("^[A-Z]+")
 	if match := uppercaseRegexp.MatchString(title); !match {
 		return fmt.Errorf("PR title must start with an uppercase letter.")
@@ -275,36 +628,31 @@ func checkTitle(_ *githubactions.Action, title string) error {
 		return fmt.Errorf("PR title must end with a latin letter or digit.")
 	}
 
-	firstWord, _, _ := strings.Cut(title, " ")
-	if strings.HasSuffix(firstWord, ":") {
+	if cfg.NoPrefix && strings.HasSuffix(firstWord, ":") {
 		return fmt.Errorf("PR title must not start with a prefix.")
 	}
 
-	doc, err := prose.NewDocument("I " + firstWord)
-	if err != nil {
-		return fmt.Errorf("error parsing PR title.")
-	}
-
-	tokens := doc.Tokens()
-	tok := tokens[1]
-
-	// imperative verbs have "VBP" tag
-	// https://github.com/jdkato/prose/tree/v2#tagging
-	if tok.Tag != "VBP" {
-		return fmt.Errorf("PR title must start with an imperative verb (got %q).", tok.Tag)
+	if cfg.ImperativeVerb {
+		if err := checkTitleVerb(cfg, firstWord); err != nil {
+			return err
+		}
 	}
 
-	if utf8.RuneCountInString(title) > 72 {
-		return fmt.Errorf("PR title must not longer than 72 unicode runes")
+	if cfg.MaxLength > 0 && utf8.RuneCountInString(title) > cfg.MaxLength {
+		return fmt.Errorf("PR title must not longer than %d unicode runes", cfg.MaxLength)
 	}
 
 	return nil
 }
 
 // checkBody checks if PR's body (description) ends with a punctuation mark.
-func checkBody(action *githubactions.Action, body string) error {
+func checkBody(action *githubactions.Action, cfg *bodyConfig, body string) error {
 	action.Debugf("checkBody:\n%s", hex.Dump([]byte(body)))
 
+	if !cfg.FinalPunctuation {
+		return nil
+	}
+
 	// it does not seem to be documented, but PR bodies use CRLF instead of LF for line breaks
 	body = strings.ReplaceAll(body, "\r\n", "\n")
 
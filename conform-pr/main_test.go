@@ -12,18 +12,23 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package main
+package conformpr
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"testing"
 
+	"github.com/google/go-github/v70/github"
 	"github.com/sethvargo/go-githubactions"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/FerretDB/github-actions/internal/graphql"
+	"github.com/FerretDB/github-actions/internal/testutil"
 )
 
 func TestRunPRChecks(t *testing.T) {
@@ -31,9 +36,13 @@ func TestRunPRChecks(t *testing.T) {
 
 	ctx := context.Background()
 	action := githubactions.New()
+	gClient, err := graphql.NewClientFromHTTP(ctx, action, testutil.HTTPClient(t, "CONFORM_TOKEN"))
+	require.NoError(t, err)
+
 	c := &checker{
 		action:  action,
-		gClient: graphql.NewClient(ctx, action, "CONFORM_TOKEN"),
+		gClient: gClient,
+		config:  defaultConfig(),
 	}
 
 	// To get node ID from PR:
@@ -57,7 +66,7 @@ func TestRunPRChecks(t *testing.T) {
 		expectedRes: []checkResult{
 			{check: "Labels"},
 			{check: "Size"},
-			{check: "Sprint", err: fmt.Errorf(`PR should have "Sprint" field set.`)},
+			{check: "Sprint", err: fmt.Errorf(`PR should have "Sprint" field set.`), severity: severityError},
 			{check: "Title"},
 			{check: "Body"},
 			{check: "Auto-merge"},
@@ -69,8 +78,9 @@ func TestRunPRChecks(t *testing.T) {
 		expectedRes: []checkResult{
 			{check: "Labels"},
 			{
-				check: "Size",
-				err:   fmt.Errorf(`PR should have "Size" field unset, got "🐋 X-Large" for project "Another test project".`),
+				check:    "Size",
+				err:      fmt.Errorf(`PR should have "Size" field unset, got "🐋 X-Large" for project "Another test project".`),
+				severity: severityError,
 			},
 			{check: "Sprint"},
 			{check: "Title"},
@@ -96,12 +106,14 @@ func TestRunPRChecks(t *testing.T) {
 		nodeID: "PR_kwDOGfwnTc5DpH8i", // https://github.com/FerretDB/github-actions/pull/120
 		expectedRes: []checkResult{
 			{
-				check: "Labels",
-				err:   fmt.Errorf(`That PR should not be merged yet.`),
+				check:    "Labels",
+				err:      fmt.Errorf(`That PR should not be merged yet.`),
+				severity: severityError,
 			},
 			{
-				check: "Labels",
-				err:   fmt.Errorf("That PR can't be merged yet; remove `not ready` label."),
+				check:    "Labels",
+				err:      fmt.Errorf("That PR can't be merged yet; remove `not ready` label."),
+				severity: severityError,
 			},
 			{
 				check: "Labels",
@@ -110,15 +122,18 @@ func TestRunPRChecks(t *testing.T) {
 						"blog/engineering, blog/marketing, code/bug, code/bug-regression, code/chore, " +
 						"code/enhancement, code/feature, deps, documentation, project.",
 				),
+				severity: severityError,
 			},
 			{check: "Size"},
 			{
-				check: "Sprint",
-				err:   fmt.Errorf(`PR should have "Sprint" field set.`),
+				check:    "Sprint",
+				err:      fmt.Errorf(`PR should have "Sprint" field set.`),
+				severity: severityError,
 			},
 			{
-				check: "Title",
-				err:   fmt.Errorf(`PR title must end with a latin letter or digit.`),
+				check:    "Title",
+				err:      fmt.Errorf(`PR title must end with a latin letter or digit.`),
+				severity: severityError,
 			},
 
 			{check: "Body"},
@@ -131,9 +146,10 @@ func TestRunPRChecks(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel()
 
-			res, community := c.runChecks(ctx, "FerretDB", tc.user, tc.nodeID)
-			assert.Equal(t, tc.expectedRes, res)
-			assert.Equal(t, tc.expectedCommunity, community)
+			rep, err := c.runChecks(ctx, "FerretDB", "github-actions", tc.user, tc.nodeID)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedRes, rep.results)
+			assert.Equal(t, tc.expectedCommunity, rep.community)
 		})
 	}
 }
@@ -186,24 +202,26 @@ func TestCheckTitle(t *testing.T) {
 	}, {
 		name:        "pull_request/title_with_invalid_imperative_verb",
 		title:       "Please do not merge this PR",
-		expectedErr: nil, // `prose` fails to detect this as a verb
+		expectedErr: errors.New("PR title must start with an imperative verb (got \"Please\"). If it is one, add it to `title.extra_verbs` configuration."),
 	}, {
 		name:        "pull_request/title_with_invalid_imperative_verb",
 		title:       "A title without an imperative verb at the beginning",
-		expectedErr: nil, // `prose` fails to detect this as a verb
+		expectedErr: errors.New("PR title must start with an imperative verb (got \"A\"). If it is one, add it to `title.extra_verbs` configuration."),
 	}, {
 		name:        "pull_request/title_with_72_unicode_runes",
-		title:       fmt.Sprintf("A%sB", string(make([]rune, 70))),
+		title:       fmt.Sprintf("Add %sB", strings.Repeat("ы", 67)),
 		expectedErr: nil,
 	}, {
 		name:        "pull_request/title_with_more_than_72_unicode_runes",
-		title:       fmt.Sprintf("A%sB", string(make([]rune, 71))),
+		title:       fmt.Sprintf("Add %sB", strings.Repeat("ы", 68)),
 		expectedErr: fmt.Errorf("PR title must not longer than 72 unicode runes"),
 	}}
 
+	cfg := defaultConfig()
+
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
-			err := checkTitle(githubactions.New(), tc.title)
+			err := checkTitle(githubactions.New(), &cfg.Title, tc.title)
 			assert.Equal(t, tc.expectedErr, err)
 		})
 	}
@@ -246,10 +264,92 @@ func TestCheckBody(t *testing.T) {
 		expectedErr: errNoPunctuation,
 	}}
 
+	cfg := defaultConfig()
+
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
-			err := checkBody(githubactions.New(), tc.body)
+			err := checkBody(githubactions.New(), &cfg.Body, tc.body)
 			assert.Equal(t, tc.expectedErr, err)
 		})
 	}
 }
+
+func TestCheckLabels(t *testing.T) {
+	cfg := &labelsConfig{
+		Blocking: []blockingLabel{
+			{Name: "wip", Message: "Work in progress."},
+		},
+		Forbidden:         []string{"question"},
+		ForbiddenPrefixes: []string{"team/"},
+		Required:          []string{"bug", "feature"},
+	}
+
+	cases := []struct {
+		name     string
+		labels   []string
+		expected []error
+	}{{
+		name:   "Valid",
+		labels: []string{"bug", "trust"},
+	}, {
+		name:   "Invalid",
+		labels: []string{"wip", "question", "team/core"},
+		expected: []error{
+			errors.New("Work in progress."),
+			errors.New("Those labels should not be applied to PRs: question, team/core."),
+			errors.New("PR must have at least one of those labels:<br />bug, feature."),
+		},
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := checkLabels(githubactions.New(), cfg, tc.labels)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestReport(t *testing.T) {
+	rep := &report{
+		results: []checkResult{
+			{check: "Labels"},
+			{check: "Sprint", err: errors.New("No sprint."), severity: severityWarning},
+			{check: "Body", err: errors.New("No dot."), severity: severityNotice},
+		},
+		user:             "AlekSi",
+		maintainerSource: "built-in list",
+	}
+
+	assert.True(t, rep.conform())
+
+	expected := "" +
+		"| Check | Status |\n" +
+		"| --- | --- |\n" +
+		"| Labels | ✅ |\n" +
+		"| Sprint | ⚠️ No sprint. |\n" +
+		"| Body | ℹ️ No dot. |\n" +
+		"\n@AlekSi is a maintainer according to built-in list.\n"
+	assert.Equal(t, expected, rep.summary())
+
+	rep.results = append(rep.results, checkResult{check: "Title", err: errors.New("No verb."), severity: severityError})
+	assert.False(t, rep.conform())
+
+	rep.fixes = []fixResult{{check: "Title", description: "Capitalized the first word."}}
+
+	pr := &github.PullRequest{
+		Number:  github.Ptr(10),
+		HTMLURL: github.Ptr("https://github.com/FerretDB/FerretDB/pull/10"),
+	}
+	b, err := json.Marshal(newResultJSON([]target{{pr: pr}}, []*report{rep}))
+	require.NoError(t, err)
+
+	expectedJSON := `{"conform":false,"prs":[{` +
+		`"number":10,"url":"https://github.com/FerretDB/FerretDB/pull/10","author":"AlekSi","conform":false,"community":false,` +
+		`"checks":[` +
+		`{"check":"Labels","passed":true},` +
+		`{"check":"Sprint","passed":false,"severity":"warning","message":"No sprint."},` +
+		`{"check":"Body","passed":false,"severity":"notice","message":"No dot."},` +
+		`{"check":"Title","passed":false,"severity":"error","message":"No verb."}],` +
+		`"fixes":[{"check":"Title","description":"Capitalized the first word."}]}]}`
+	assert.JSONEq(t, expectedJSON, string(b))
+}
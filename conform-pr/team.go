@@ -12,9 +12,21 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package main
+package conformpr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sethvargo/go-githubactions"
+	"golang.org/x/exp/slices"
+)
 
 // FerretDB organization members.
+//
+// It is used only as an offline fallback when GitHub API can't be queried;
+// see resolveMaintainer.
 var maintainers = map[string]struct{}{
 	"ferretdb-bot": {},
 	"ptrfarkas":    {},
@@ -23,3 +35,68 @@ var maintainers = map[string]struct{}{
 	"Fashander":    {},
 	"chilagrow":    {},
 }
+
+// maintainerClient is a subset of GitHub GraphQL API client methods used by resolveMaintainer.
+type maintainerClient interface {
+	IsTeamMember(ctx context.Context, org, team, user string) (bool, error)
+	GetRepositoryPermission(ctx context.Context, owner, repo, user string) (string, error)
+}
+
+// resolveMaintainer returns true if the user is a maintainer of the given repository,
+// and a human-readable description of the source of that information.
+func (c *checker) resolveMaintainer(ctx context.Context, owner, repo, user string) (bool, string) {
+	return resolveMaintainer(ctx, c.action, c.gClient, &c.config.Maintainers, owner, repo, user)
+}
+
+// resolveMaintainer returns true if the user is a maintainer of the given repository,
+// and a human-readable description of the source of that information.
+//
+// Organization team membership is checked first, then repository permission level.
+// If any of GitHub API queries fail and other queries do not confirm that the user is a maintainer,
+// the built-in list is used, and the source describes failed queries.
+func resolveMaintainer(ctx context.Context, action *githubactions.Action, client maintainerClient, cfg *maintainersConfig, owner, repo, user string) (bool, string) { //nolint:lll // for readability
+	var queried bool
+	var failed []string
+
+	if cfg.Team != "" {
+		member, err := client.IsTeamMember(ctx, owner, cfg.Team, user)
+		if err != nil {
+			action.Warningf("Failed to check team membership: %s.", err)
+			failed = append(failed, "team membership")
+		} else {
+			queried = true
+
+			if member {
+				return true, fmt.Sprintf("member of `%s/%s` team", owner, cfg.Team)
+			}
+		}
+	}
+
+	if len(cfg.Permissions) > 0 {
+		permission, err := client.GetRepositoryPermission(ctx, owner, repo, user)
+		if err != nil {
+			action.Warningf("Failed to get repository permission: %s.", err)
+			failed = append(failed, "repository permission")
+		} else {
+			queried = true
+
+			if slices.Contains(cfg.Permissions, permission) {
+				return true, fmt.Sprintf("`%s` permission for `%s/%s`", permission, owner, repo)
+			}
+		}
+	}
+
+	_, maintainer := maintainers[user]
+
+	switch {
+	case len(failed) == 0 && queried:
+		return false, "GitHub API"
+	case len(failed) == 0:
+		return maintainer, "built-in list"
+	case queried:
+		// a successful query did not confirm, but a failed one could have
+		return maintainer, fmt.Sprintf("built-in list (GitHub API %s query failed)", strings.Join(failed, " and "))
+	default:
+		return maintainer, "built-in list (GitHub API queries failed)"
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conformpr
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/sethvargo/go-githubactions"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeMaintainerClient is a maintainerClient with canned responses.
+type fakeMaintainerClient struct {
+	member        bool
+	memberErr     error
+	permission    string
+	permissionErr error
+}
+
+// IsTeamMember implements maintainerClient.
+func (f *fakeMaintainerClient) IsTeamMember(context.Context, string, string, string) (bool, error) {
+	return f.member, f.memberErr
+}
+
+// GetRepositoryPermission implements maintainerClient.
+func (f *fakeMaintainerClient) GetRepositoryPermission(context.Context, string, string, string) (string, error) {
+	return f.permission, f.permissionErr
+}
+
+func TestResolveMaintainer(t *testing.T) {
+	t.Parallel()
+
+	errFailed := errors.New("failed")
+
+	cases := []struct {
+		name       string
+		cfg        maintainersConfig
+		client     fakeMaintainerClient
+		user       string
+		maintainer bool
+		source     string
+	}{{
+		name:       "TeamMember",
+		cfg:        maintainersConfig{Team: "core", Permissions: []string{"WRITE"}},
+		client:     fakeMaintainerClient{member: true},
+		user:       "someone",
+		maintainer: true,
+		source:     "member of `FerretDB/core` team",
+	}, {
+		name:       "Permission",
+		cfg:        maintainersConfig{Team: "core", Permissions: []string{"WRITE"}},
+		client:     fakeMaintainerClient{permission: "WRITE"},
+		user:       "someone",
+		maintainer: true,
+		source:     "`WRITE` permission for `FerretDB/FerretDB`",
+	}, {
+		name:   "Community",
+		cfg:    maintainersConfig{Team: "core", Permissions: []string{"WRITE"}},
+		client: fakeMaintainerClient{permission: "READ"},
+		user:   "AlekSi",
+		source: "GitHub API",
+	}, {
+		name:       "TeamFailedPermissionConfirmed",
+		cfg:        maintainersConfig{Team: "core", Permissions: []string{"WRITE"}},
+		client:     fakeMaintainerClient{memberErr: errFailed, permission: "WRITE"},
+		user:       "someone",
+		maintainer: true,
+		source:     "`WRITE` permission for `FerretDB/FerretDB`",
+	}, {
+		name:       "TeamFailedPermissionNotConfirmed",
+		cfg:        maintainersConfig{Team: "core", Permissions: []string{"WRITE"}},
+		client:     fakeMaintainerClient{memberErr: errFailed, permission: "READ"},
+		user:       "AlekSi",
+		maintainer: true,
+		source:     "built-in list (GitHub API team membership query failed)",
+	}, {
+		name:   "PermissionFailedNotTeamMember",
+		cfg:    maintainersConfig{Team: "core", Permissions: []string{"WRITE"}},
+		client: fakeMaintainerClient{permissionErr: errFailed},
+		user:   "someone",
+		source: "built-in list (GitHub API repository permission query failed)",
+	}, {
+		name:       "AllFailed",
+		cfg:        maintainersConfig{Team: "core", Permissions: []string{"WRITE"}},
+		client:     fakeMaintainerClient{memberErr: errFailed, permissionErr: errFailed},
+		user:       "AlekSi",
+		maintainer: true,
+		source:     "built-in list (GitHub API queries failed)",
+	}, {
+		name:   "NotConfigured",
+		cfg:    maintainersConfig{},
+		client: fakeMaintainerClient{memberErr: errFailed, permissionErr: errFailed},
+		user:   "someone",
+		source: "built-in list",
+	}}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			action := githubactions.New(githubactions.WithWriter(io.Discard))
+
+			maintainer, source := resolveMaintainer(
+				context.Background(), action, &tc.client, &tc.cfg, "FerretDB", "FerretDB", tc.user,
+			)
+			assert.Equal(t, tc.maintainer, maintainer)
+			assert.Equal(t, tc.source, source)
+		})
+	}
+}
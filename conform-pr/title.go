@@ -0,0 +1,171 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conformpr
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/exp/slices"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed verbs.yml
+var verbsFile []byte
+
+// verbs is a lexicon loaded from verbs.yml.
+var verbs = mustLoadLexicon(verbsFile)
+
+// lexicon contains known imperative verbs and non-imperative words.
+type lexicon struct {
+	imperative    map[string]struct{}
+	nonImperative map[string]string
+}
+
+// mustLoadLexicon parses lexicon file content, panicking on error.
+func mustLoadLexicon(b []byte) *lexicon {
+	var f struct {
+		Imperative    []string          `yaml:"imperative"`
+		NonImperative map[string]string `yaml:"non_imperative"`
+	}
+
+	if err := yaml.Unmarshal(b, &f); err != nil {
+		panic(fmt.Sprintf("invalid lexicon: %s", err))
+	}
+
+	l := &lexicon{
+		imperative:    make(map[string]struct{}, len(f.Imperative)),
+		nonImperative: f.NonImperative,
+	}
+
+	for _, v := range f.Imperative {
+		l.imperative[v] = struct{}{}
+	}
+
+	return l
+}
+
+// isVerb returns true if the given lowercase word is a known imperative verb.
+func (l *lexicon) isVerb(word string, extra []string) bool {
+	if _, ok := l.imperative[word]; ok {
+		return true
+	}
+
+	return slices.Contains(extra, word)
+}
+
+// checkImperative checks if the given lowercase word is an imperative verb.
+//
+// If it is not, the imperative form is suggested when possible.
+func (l *lexicon) checkImperative(word string, extra []string) (bool, string) {
+	if l.isVerb(word, extra) {
+		return true, ""
+	}
+
+	if suggestion, ok := l.nonImperative[word]; ok {
+		return false, suggestion
+	}
+
+	// hyphenated verbs like "re-enable" or "auto-generate"
+	if prefix, rest, ok := strings.Cut(word, "-"); ok && prefix != "" && rest != "" {
+		ok, suggestion := l.checkImperative(rest, extra)
+		if ok || suggestion == "" {
+			return ok, suggestion
+		}
+
+		return false, prefix + "-" + suggestion
+	}
+
+	// past tense, gerund, and third person forms of known verbs like "added" or "fixes"
+	for _, stem := range stems(word) {
+		if l.isVerb(stem, extra) {
+			return false, stem
+		}
+	}
+
+	return false, ""
+}
+
+// stems returns possible base forms of the given lowercase word.
+func stems(word string) []string {
+	var res []string
+
+	cut := func(suffix string, replacements ...string) {
+		base, ok := strings.CutSuffix(word, suffix)
+		if !ok || len(base) < 2 {
+			return
+		}
+
+		for _, r := range replacements {
+			res = append(res, base+r)
+		}
+
+		// doubled final consonant like "dropped" or "skipping"
+		if n := len(base); base[n-1] == base[n-2] {
+			res = append(res, base[:n-1])
+		}
+	}
+
+	cut("ied", "y")
+	cut("ies", "y")
+	cut("ed", "", "e")
+	cut("ing", "", "e")
+	cut("es", "")
+	cut("s", "")
+
+	return res
+}
+
+// checkTitleVerb checks that the title's first word is an imperative verb.
+func checkTitleVerb(cfg *titleConfig, firstWord string) error {
+	if strings.HasPrefix(firstWord, "`") {
+		return fmt.Errorf("PR title must start with an imperative verb, not with an identifier (got %s).", firstWord)
+	}
+
+	word := strings.ToLower(strings.TrimRight(firstWord, ",;"))
+
+	extra := make([]string, len(cfg.ExtraVerbs))
+	for i, v := range cfg.ExtraVerbs {
+		extra[i] = strings.ToLower(v)
+	}
+
+	ok, suggestion := verbs.checkImperative(word, extra)
+	if ok {
+		return nil
+	}
+
+	if suggestion != "" {
+		return fmt.Errorf("PR title must start with an imperative verb: use %q instead of %q.", capitalize(suggestion), firstWord)
+	}
+
+	return fmt.Errorf(
+		"PR title must start with an imperative verb (got %q). "+
+			"If it is one, add it to `title.extra_verbs` configuration.",
+		firstWord,
+	)
+}
+
+// capitalize returns s with the first letter in upper case.
+func capitalize(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError {
+		return s
+	}
+
+	return string(unicode.ToUpper(r)) + s[size:]
+}
@@ -0,0 +1,133 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conformpr
+
+import (
+	"testing"
+
+	"github.com/sethvargo/go-githubactions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTitleCorpus checks titles of FerretDB PRs, and their variants that should be rejected.
+func TestTitleCorpus(t *testing.T) {
+	valid := []string{
+		"Add SQLite backend",
+		"Add `$setOnInsert` update operator",
+		"Address review comments",
+		"Backport fixes to `releases/1.24`",
+		"Bump deps",
+		"Bump github.com/go-task/task/v3 from 3.14.0 to 3.14.1 in /tools",
+		"Document `not ready` issues label",
+		"Dockerize integration tests",
+		"Enable auto-merge for dependabot PRs",
+		"Fix `$` path errors for sort",
+		"Implement `$sort` aggregation pipeline stage",
+		"Improve `listCollections` performance",
+		"Make `checkSize` results stable",
+		"Migrate to `ProjectV2`",
+		"Re-enable `gocritic` linter",
+		"Refactor `handlers/pg` to use `pgdb`",
+		"Remove `tigris` backend",
+		"Speed up tests",
+		"Support `$elemMatch` projection operator",
+		"Tweak issue templates",
+		"Unskip passing tests",
+		"Update contributing guidelines",
+		"Use `go-task` for development",
+	}
+
+	cfg := defaultConfig()
+
+	for _, title := range valid {
+		t.Run(title, func(t *testing.T) {
+			assert.NoError(t, checkTitle(githubactions.New(), &cfg.Title, title))
+		})
+	}
+
+	invalid := []struct {
+		title    string
+		expected string
+	}{{
+		title:    "Added `$sort` support",
+		expected: `PR title must start with an imperative verb: use "Add" instead of "Added".`,
+	}, {
+		title:    "Fixes #123",
+		expected: `PR title must start with an imperative verb: use "Fix" instead of "Fixes".`,
+	}, {
+		title:    "Fixing flaky test",
+		expected: `PR title must start with an imperative verb: use "Fix" instead of "Fixing".`,
+	}, {
+		title:    "Implemented `count` command",
+		expected: `PR title must start with an imperative verb: use "Implement" instead of "Implemented".`,
+	}, {
+		title:    "Copied test files",
+		expected: `PR title must start with an imperative verb: use "Copy" instead of "Copied".`,
+	}, {
+		title:    "Dropped Go 1.19 support",
+		expected: `PR title must start with an imperative verb: use "Drop" instead of "Dropped".`,
+	}, {
+		title:    "Re-enabled `gocritic` linter",
+		expected: `PR title must start with an imperative verb: use "Re-enable" instead of "Re-enabled".`,
+	}, {
+		title:    "New `explain` command",
+		expected: `PR title must start with an imperative verb: use "Add" instead of "New".`,
+	}, {
+		title:    "Docs update",
+		expected: `PR title must start with an imperative verb: use "Document" instead of "Docs".`,
+	}, {
+		title:    "`pgdb` refactoring",
+		expected: "PR title must start with an imperative verb, not with an identifier (got `pgdb`).",
+	}, {
+		title: "Sqlite backend",
+		expected: `PR title must start with an imperative verb (got "Sqlite"). ` +
+			"If it is one, add it to `title.extra_verbs` configuration.",
+	}, {
+		// not verbs despite "-ize", "-ise", and "-ify" suffixes
+		title: "Noise reduction",
+		expected: `PR title must start with an imperative verb (got "Noise"). ` +
+			"If it is one, add it to `title.extra_verbs` configuration.",
+	}, {
+		title: "Otherwise fix",
+		expected: `PR title must start with an imperative verb (got "Otherwise"). ` +
+			"If it is one, add it to `title.extra_verbs` configuration.",
+	}, {
+		title: "Premise of the new cache",
+		expected: `PR title must start with an imperative verb (got "Premise"). ` +
+			"If it is one, add it to `title.extra_verbs` configuration.",
+	}, {
+		title: "Enterprise support",
+		expected: `PR title must start with an imperative verb (got "Enterprise"). ` +
+			"If it is one, add it to `title.extra_verbs` configuration.",
+	}, {
+		title: "Size estimation fix",
+		expected: `PR title must start with an imperative verb (got "Size"). ` +
+			"If it is one, add it to `title.extra_verbs` configuration.",
+	}}
+
+	for _, tc := range invalid {
+		t.Run(tc.title, func(t *testing.T) {
+			err := checkTitle(githubactions.New(), &cfg.Title, tc.title)
+			require.Error(t, err)
+			assert.Equal(t, tc.expected, err.Error())
+		})
+	}
+
+	t.Run("ExtraVerbs", func(t *testing.T) {
+		cfg := titleConfig{ImperativeVerb: true, ExtraVerbs: []string{"Sqlite"}}
+		assert.NoError(t, checkTitle(githubactions.New(), &cfg, "Sqlite backend"))
+	})
+}
@@ -12,11 +12,11 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package main
+// Package detectmatchingpr implements `detect-matching-pr` action.
+package detectmatchingpr
 
 import (
 	"context"
-	"flag"
 	"fmt"
 	"strconv"
 
@@ -24,24 +24,28 @@ import (
 	"github.com/sethvargo/go-githubactions"
 
 	"github.com/FerretDB/github-actions/internal"
+	"github.com/FerretDB/github-actions/internal/output"
 )
 
-func main() {
-	flag.Parse()
-
-	ctx := context.Background()
-	action := githubactions.New()
-	client := internal.GitHubClient(action, "GITHUB_TOKEN")
+// Run runs the action.
+func Run(ctx context.Context, action *githubactions.Action) error {
+	client, err := internal.GitHubClient(ctx, action, "GITHUB_TOKEN")
+	if err != nil {
+		return fmt.Errorf("failed to create GitHub client: %w", err)
+	}
 
 	internal.DebugEnv(action)
 
 	result, err := detect(ctx, action, client)
+	internal.AddRateLimitSummary(action)
+
 	if err != nil {
-		action.Fatalf("%s", err)
+		return err
 	}
 
 	action.Infof("Detected: %+v.", result)
 	action.Noticef("Detected: %s", result.url)
+	output.AddSummary(action, result.summary())
 
 	action.SetOutput("owner", result.owner)
 	action.SetOutput("repo", result.repo)
@@ -51,6 +55,8 @@ func main() {
 	if result.number != 0 {
 		action.SetOutput("number", strconv.Itoa(result.number))
 	}
+
+	return output.SetResult(action, result.json())
 }
 
 // branchID represents a named branch in owner's repo.
@@ -68,6 +74,40 @@ type result struct {
 	url    string // https://github.com/AlekSi/dance/tree/feature-branch or https://github.com/AlekSi/dance/pull/1
 }
 
+// resultJSON is a result encoded as `json` output.
+type resultJSON struct {
+	Owner  string `json:"owner"`
+	Repo   string `json:"repo"`
+	Branch string `json:"branch,omitempty"`
+	Number int    `json:"number,omitempty"`
+	URL    string `json:"url"`
+}
+
+// json returns result for `json` output.
+func (r *result) json() *resultJSON {
+	return &resultJSON{
+		Owner:  r.owner,
+		Repo:   r.repo,
+		Branch: r.branch,
+		Number: r.number,
+		URL:    r.url,
+	}
+}
+
+// summary returns detection result in Markdown.
+func (r *result) summary() string {
+	table := output.NewTable("Owner", "Repo", "Branch", "PR", "URL")
+
+	var number string
+	if r.number != 0 {
+		number = "#" + strconv.Itoa(r.number)
+	}
+
+	table.Add(r.owner, r.repo, r.branch, number, r.url)
+
+	return "Detected matching PR or branch:\n\n" + table.String()
+}
+
 func detect(ctx context.Context, action *githubactions.Action, client *github.Client) (*result, error) {
 	event, err := internal.ReadEvent(action)
 	if err != nil {
@@ -77,33 +117,37 @@ func detect(ctx context.Context, action *githubactions.Action, client *github.Cl
 	var base, head branchID
 
 	// extract information from event
-	switch event := event.(type) {
+	switch payload := event.Payload.(type) {
 	case *github.PullRequestEvent:
-		base.owner = *event.PullRequest.Base.Repo.Owner.Login
-		base.repo = *event.PullRequest.Base.Repo.Name
-		base.branch = *event.PullRequest.Base.Ref
+		base.owner = *payload.PullRequest.Base.Repo.Owner.Login
+		base.repo = *payload.PullRequest.Base.Repo.Name
+		base.branch = *payload.PullRequest.Base.Ref
 
-		head.owner = *event.PullRequest.Head.Repo.Owner.Login
-		head.repo = *event.PullRequest.Head.Repo.Name
-		head.branch = *event.PullRequest.Head.Ref
+		head.owner = *payload.PullRequest.Head.Repo.Owner.Login
+		head.repo = *payload.PullRequest.Head.Repo.Name
+		head.branch = *payload.PullRequest.Head.Ref
 
 	case *github.PushEvent:
-		baseRef := event.GetBaseRef()
-		ref := event.GetRef()
+		baseRef := payload.GetBaseRef()
+		ref := payload.GetRef()
 		if baseRef != "" || ref != "refs/heads/main" {
 			return nil, fmt.Errorf("detect: unhandled push to %q / %q", baseRef, ref)
 		}
 
-		base.owner = *event.Repo.Owner.Login
-		base.repo = *event.Repo.Name
+		base.owner, base.repo = event.Repo()
+		base.branch = "main"
+
+		head = base
+
+	case *internal.ScheduleEvent:
+		// scheduled workflows run on the default branch
+		base.owner, base.repo = event.Repo()
 		base.branch = "main"
 
-		head.owner = *event.Repo.Owner.Login
-		head.repo = *event.Repo.Name
-		head.branch = "main"
+		head = base
 
 	default:
-		return nil, fmt.Errorf("detect: unhandled event type %T", event)
+		return nil, fmt.Errorf("detect: unhandled %q event", event.Name)
 	}
 
 	// figure out the other repo (FerretDB or dance)
@@ -12,7 +12,7 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package main
+package detectmatchingpr
 
 import (
 	"context"
@@ -24,7 +24,6 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
-	"github.com/FerretDB/github-actions/internal"
 	"github.com/FerretDB/github-actions/internal/testutil"
 )
 
@@ -36,10 +35,13 @@ func TestDetect(t *testing.T) {
 			"GITHUB_EVENT_NAME": "pull_request",
 			"GITHUB_EVENT_PATH": filepath.Join("..", "testdata", "pull_request_self.json"),
 			"GITHUB_TOKEN":      os.Getenv("GITHUB_TOKEN"),
+			"GITHUB_REPOSITORY": "",
+			"GITHUB_SHA":        "",
+			"GITHUB_ACTOR":      "",
 		})
 
 		action := githubactions.New(githubactions.WithGetenv(getEnv))
-		actual, err := detect(ctx, action, internal.GitHubClient(action, "GITHUB_TOKEN"))
+		actual, err := detect(ctx, action, testutil.GitHubClient(t, "GITHUB_TOKEN"))
 		require.NoError(t, err)
 		expected := &result{
 			owner:  "AlekSi",
@@ -55,10 +57,13 @@ func TestDetect(t *testing.T) {
 			"GITHUB_EVENT_NAME": "pull_request",
 			"GITHUB_EVENT_PATH": filepath.Join("..", "testdata", "pull_request_fork.json"),
 			"GITHUB_TOKEN":      os.Getenv("GITHUB_TOKEN"),
+			"GITHUB_REPOSITORY": "",
+			"GITHUB_SHA":        "",
+			"GITHUB_ACTOR":      "",
 		})
 
 		action := githubactions.New(githubactions.WithGetenv(getEnv))
-		actual, err := detect(ctx, action, internal.GitHubClient(action, "GITHUB_TOKEN"))
+		actual, err := detect(ctx, action, testutil.GitHubClient(t, "GITHUB_TOKEN"))
 		require.NoError(t, err)
 		expected := &result{
 			owner:  "FerretDB",
@@ -74,10 +79,13 @@ func TestDetect(t *testing.T) {
 			"GITHUB_EVENT_NAME": "pull_request",
 			"GITHUB_EVENT_PATH": filepath.Join("..", "testdata", "pull_request_dependabot.json"),
 			"GITHUB_TOKEN":      os.Getenv("GITHUB_TOKEN"),
+			"GITHUB_REPOSITORY": "",
+			"GITHUB_SHA":        "",
+			"GITHUB_ACTOR":      "",
 		})
 
 		action := githubactions.New(githubactions.WithGetenv(getEnv))
-		actual, err := detect(ctx, action, internal.GitHubClient(action, "GITHUB_TOKEN"))
+		actual, err := detect(ctx, action, testutil.GitHubClient(t, "GITHUB_TOKEN"))
 		require.NoError(t, err)
 		expected := &result{
 			owner:  "AlekSi",
@@ -93,10 +101,13 @@ func TestDetect(t *testing.T) {
 			"GITHUB_EVENT_NAME": "pull_request_target",
 			"GITHUB_EVENT_PATH": filepath.Join("..", "testdata", "pull_request_target_self.json"),
 			"GITHUB_TOKEN":      os.Getenv("GITHUB_TOKEN"),
+			"GITHUB_REPOSITORY": "",
+			"GITHUB_SHA":        "",
+			"GITHUB_ACTOR":      "",
 		})
 
 		action := githubactions.New(githubactions.WithGetenv(getEnv))
-		actual, err := detect(ctx, action, internal.GitHubClient(action, "GITHUB_TOKEN"))
+		actual, err := detect(ctx, action, testutil.GitHubClient(t, "GITHUB_TOKEN"))
 		require.NoError(t, err)
 		expected := &result{
 			owner:  "AlekSi",
@@ -112,10 +123,13 @@ func TestDetect(t *testing.T) {
 			"GITHUB_EVENT_NAME": "pull_request_target",
 			"GITHUB_EVENT_PATH": filepath.Join("..", "testdata", "pull_request_target_fork.json"),
 			"GITHUB_TOKEN":      os.Getenv("GITHUB_TOKEN"),
+			"GITHUB_REPOSITORY": "",
+			"GITHUB_SHA":        "",
+			"GITHUB_ACTOR":      "",
 		})
 
 		action := githubactions.New(githubactions.WithGetenv(getEnv))
-		actual, err := detect(ctx, action, internal.GitHubClient(action, "GITHUB_TOKEN"))
+		actual, err := detect(ctx, action, testutil.GitHubClient(t, "GITHUB_TOKEN"))
 		require.NoError(t, err)
 		expected := &result{
 			owner:  "FerretDB",
@@ -131,10 +145,13 @@ func TestDetect(t *testing.T) {
 			"GITHUB_EVENT_NAME": "pull_request_target",
 			"GITHUB_EVENT_PATH": filepath.Join("..", "testdata", "pull_request_target_dependabot.json"),
 			"GITHUB_TOKEN":      os.Getenv("GITHUB_TOKEN"),
+			"GITHUB_REPOSITORY": "",
+			"GITHUB_SHA":        "",
+			"GITHUB_ACTOR":      "",
 		})
 
 		action := githubactions.New(githubactions.WithGetenv(getEnv))
-		actual, err := detect(ctx, action, internal.GitHubClient(action, "GITHUB_TOKEN"))
+		actual, err := detect(ctx, action, testutil.GitHubClient(t, "GITHUB_TOKEN"))
 		require.NoError(t, err)
 		expected := &result{
 			owner:  "AlekSi",
@@ -150,10 +167,13 @@ func TestDetect(t *testing.T) {
 			"GITHUB_EVENT_NAME": "push",
 			"GITHUB_EVENT_PATH": filepath.Join("..", "testdata", "push.json"),
 			"GITHUB_TOKEN":      os.Getenv("GITHUB_TOKEN"),
+			"GITHUB_REPOSITORY": "",
+			"GITHUB_SHA":        "",
+			"GITHUB_ACTOR":      "",
 		})
 
 		action := githubactions.New(githubactions.WithGetenv(getEnv))
-		actual, err := detect(ctx, action, internal.GitHubClient(action, "GITHUB_TOKEN"))
+		actual, err := detect(ctx, action, testutil.GitHubClient(t, "GITHUB_TOKEN"))
 		require.NoError(t, err)
 		expected := &result{
 			owner:  "AlekSi",
@@ -169,10 +189,13 @@ func TestDetect(t *testing.T) {
 			"GITHUB_EVENT_NAME": "schedule",
 			"GITHUB_EVENT_PATH": filepath.Join("..", "testdata", "schedule.json"),
 			"GITHUB_TOKEN":      os.Getenv("GITHUB_TOKEN"),
+			"GITHUB_REPOSITORY": "",
+			"GITHUB_SHA":        "",
+			"GITHUB_ACTOR":      "",
 		})
 
 		action := githubactions.New(githubactions.WithGetenv(getEnv))
-		actual, err := detect(ctx, action, internal.GitHubClient(action, "GITHUB_TOKEN"))
+		actual, err := detect(ctx, action, testutil.GitHubClient(t, "GITHUB_TOKEN"))
 		require.NoError(t, err)
 		expected := &result{
 			owner:  "AlekSi",
@@ -183,3 +206,20 @@ func TestDetect(t *testing.T) {
 		assert.Equal(t, expected, actual)
 	})
 }
+
+func TestResultSummary(t *testing.T) {
+	t.Parallel()
+
+	r := &result{
+		owner:  "FerretDB",
+		repo:   "dance",
+		number: 47,
+		url:    "https://github.com/FerretDB/dance/pull/47",
+	}
+
+	expected := "Detected matching PR or branch:\n\n" +
+		"| Owner | Repo | Branch | PR | URL |\n" +
+		"| --- | --- | --- | --- | --- |\n" +
+		"| FerretDB | dance |  | #47 | https://github.com/FerretDB/dance/pull/47 |\n"
+	assert.Equal(t, expected, r.summary())
+}
@@ -0,0 +1,328 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package extractdockertag
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/sethvargo/go-githubactions"
+	"golang.org/x/exp/slices"
+
+	"github.com/FerretDB/github-actions/internal"
+)
+
+// defaultConfigPath is the configuration file path relative to the workspace
+// that is used when `config` input is not set.
+const defaultConfigPath = ".github/extract-docker-tag.yml"
+
+// configVersion is the only supported configuration file version.
+const configVersion = 1
+
+//go:embed default.yml
+var defaultConfigFile []byte
+
+// Kinds of refs that produce images.
+const (
+	refPullRequest = "pull_request"
+	refBranch      = "branch"
+	refTag         = "tag"
+	refMergeGroup  = "merge_group"
+)
+
+// knownRefs contains all kinds of refs.
+var knownRefs = []string{refPullRequest, refBranch, refTag, refMergeGroup}
+
+// Image families' outputs.
+const (
+	outputAllInOne    = "all_in_one"
+	outputDevelopment = "development"
+	outputProduction  = "production"
+)
+
+// knownOutputs contains all image families' outputs.
+var knownOutputs = []string{outputAllInOne, outputDevelopment, outputProduction}
+
+// Scopes of floating tags like `latest`.
+const (
+	floatingAll   = "all"
+	floatingMajor = "major"
+	floatingMinor = "minor"
+)
+
+// knownFloating contains all scopes of floating tags.
+var knownFloating = []string{floatingAll, floatingMajor, floatingMinor}
+
+// knownPlaceholders contains all placeholders that could be used in templates.
+var knownPlaceholders = []string{"owner", "name", "branch", "version", "major", "minor", "patch", "prerelease", "sha"}
+
+// placeholderRe matches placeholders like `{owner}`.
+var placeholderRe = regexp.MustCompile(`\{([^{}]*)\}`)
+
+// config represents extract-docker-tag configuration file.
+//
+// See default.yml for an example.
+type config struct {
+	Version    int              `yaml:"version"`
+	Branches   []string         `yaml:"branches"`
+	Registries []registryConfig `yaml:"registries"`
+	Families   []familyConfig   `yaml:"families"`
+	Tags       []tagConfig      `yaml:"tags"`
+
+	// CheckPrecedence enables checking of existing git tags in the workspace,
+	// so floating tags are moved only to the highest version in their scope.
+	CheckPrecedence bool `yaml:"check_precedence"`
+}
+
+// registryConfig configures a registry images are published to.
+type registryConfig struct {
+	Name string `yaml:"name"`
+
+	// Prefix is prepended to the image name, like `ghcr.io/{owner}/`.
+	Prefix string `yaml:"prefix"`
+
+	// Repositories lists patterns of repositories published to that registry; all if empty.
+	Repositories []string `yaml:"repositories"`
+}
+
+// familyConfig configures a family of images, like development images.
+type familyConfig struct {
+	// Output is one of knownOutputs.
+	Output string `yaml:"output"`
+
+	// Image is the image name without registry prefix and tag, like `{name}-dev`.
+	Image string `yaml:"image"`
+
+	// Refs lists kinds of refs that produce images of that family.
+	Refs []string `yaml:"refs"`
+
+	// Repositories lists patterns of repositories that produce images of that family; all if empty.
+	Repositories []string `yaml:"repositories"`
+
+	// Registries lists names of registries images are published to; all if empty.
+	Registries []string `yaml:"registries"`
+}
+
+// tagConfig configures an image tag.
+type tagConfig struct {
+	// Ref is a kind of ref that produces that tag.
+	Ref string `yaml:"ref"`
+
+	// Template is the tag template, like `pr-{branch}`.
+	Template string `yaml:"template"`
+
+	// Stable is true if that tag is used only for versions without prerelease part.
+	Stable bool `yaml:"stable"`
+
+	// Floating is a scope of the tag that is moved to new versions, one of knownFloating;
+	// empty for tags that are not moved.
+	Floating string `yaml:"floating"`
+}
+
+// parseConfig parses and validates configuration file content.
+//
+// Both YAML and JSON are accepted. Unknown fields are rejected.
+func parseConfig(b []byte) (*config, error) {
+	var c config
+	if err := internal.DecodeConfig(b, &c); err != nil {
+		return nil, err
+	}
+
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+// validate checks that configuration is valid.
+func (c *config) validate() error {
+	if c.Version != configVersion {
+		return fmt.Errorf("unsupported version %d, expected %d", c.Version, configVersion)
+	}
+
+	var registries []string
+
+	for i, r := range c.Registries {
+		if r.Name == "" {
+			return fmt.Errorf("registries[%d].name: must be set", i)
+		}
+
+		if slices.Contains(registries, r.Name) {
+			return fmt.Errorf("registries[%d].name: duplicate name %q", i, r.Name)
+		}
+
+		registries = append(registries, r.Name)
+
+		if err := validateTemplate(r.Prefix); err != nil {
+			return fmt.Errorf("registries[%d].prefix: %w", i, err)
+		}
+	}
+
+	if len(c.Families) == 0 {
+		return errors.New("families: must be set")
+	}
+
+	for i, f := range c.Families {
+		if !slices.Contains(knownOutputs, f.Output) {
+			return fmt.Errorf(
+				"families[%d].output: unexpected value %q, expected one of: %s",
+				i, f.Output, strings.Join(knownOutputs, ", "),
+			)
+		}
+
+		if f.Image == "" {
+			return fmt.Errorf("families[%d].image: must be set", i)
+		}
+
+		if err := validateTemplate(f.Image); err != nil {
+			return fmt.Errorf("families[%d].image: %w", i, err)
+		}
+
+		for j, ref := range f.Refs {
+			if !slices.Contains(knownRefs, ref) {
+				return fmt.Errorf(
+					"families[%d].refs[%d]: unexpected value %q, expected one of: %s",
+					i, j, ref, strings.Join(knownRefs, ", "),
+				)
+			}
+		}
+
+		for j, name := range f.Registries {
+			if !slices.Contains(registries, name) {
+				return fmt.Errorf("families[%d].registries[%d]: unknown registry %q", i, j, name)
+			}
+		}
+	}
+
+	for i, t := range c.Tags {
+		if !slices.Contains(knownRefs, t.Ref) {
+			return fmt.Errorf("tags[%d].ref: unexpected value %q, expected one of: %s", i, t.Ref, strings.Join(knownRefs, ", "))
+		}
+
+		if t.Template == "" {
+			return fmt.Errorf("tags[%d].template: must be set", i)
+		}
+
+		if err := validateTemplate(t.Template); err != nil {
+			return fmt.Errorf("tags[%d].template: %w", i, err)
+		}
+
+		if t.Floating != "" && !slices.Contains(knownFloating, t.Floating) {
+			return fmt.Errorf(
+				"tags[%d].floating: unexpected value %q, expected one of: %s",
+				i, t.Floating, strings.Join(knownFloating, ", "),
+			)
+		}
+	}
+
+	return nil
+}
+
+// validateTemplate checks that template contains only known placeholders.
+func validateTemplate(template string) error {
+	for _, m := range placeholderRe.FindAllStringSubmatch(template, -1) {
+		if !slices.Contains(knownPlaceholders, m[1]) {
+			return fmt.Errorf("unknown placeholder %q, expected one of: %s", m[0], strings.Join(knownPlaceholders, ", "))
+		}
+	}
+
+	return nil
+}
+
+// expand replaces placeholders in template with values.
+func expand(template string, values map[string]string) string {
+	return placeholderRe.ReplaceAllStringFunc(template, func(p string) string {
+		return values[p[1:len(p)-1]]
+	})
+}
+
+// matches returns true if s matches any of the patterns.
+//
+// Matching is case-insensitive; `*` matches any characters.
+func matches(patterns []string, s string) bool {
+	s = strings.ToLower(s)
+
+	for _, p := range patterns {
+		re := "^" + strings.ReplaceAll(regexp.QuoteMeta(strings.ToLower(p)), `\*`, ".*") + "$"
+		if regexp.MustCompile(re).MatchString(s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// defaultConfig returns built-in configuration with FerretDB rules.
+func defaultConfig() *config {
+	c, err := parseConfig(defaultConfigFile)
+	if err != nil {
+		panic(fmt.Sprintf("invalid default configuration: %s", err))
+	}
+
+	return c
+}
+
+// loadConfig loads configuration from the file set by `config` input
+// (relative to GITHUB_WORKSPACE).
+//
+// If input is not set and the default file does not exist, built-in configuration is returned.
+//
+// For pull requests, the file comes from the PR branch, so it can't change registries;
+// see trustedRegistries.
+func loadConfig(action *githubactions.Action) (*config, error) {
+	c, err := internal.LoadConfig(action, defaultConfigPath, parseConfig, defaultConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if event := action.Getenv("GITHUB_EVENT_NAME"); event == "pull_request" || event == "pull_request_target" {
+		c.Registries = trustedRegistries(action, c.Registries, defaultConfig().Registries)
+	}
+
+	return c, nil
+}
+
+// trustedRegistries returns built-in registries with the same names as configured ones,
+// so prefixes and repositories allowlists (that keep forks and other repositories from publishing images)
+// can't be changed by untrusted configuration.
+//
+// Configured registries that are not built-in are not used; warnings are logged for them.
+func trustedRegistries(action *githubactions.Action, configured, builtin []registryConfig) []registryConfig {
+	res := make([]registryConfig, 0, len(configured))
+
+	for _, r := range configured {
+		i := slices.IndexFunc(builtin, func(b registryConfig) bool { return b.Name == r.Name })
+		if i < 0 {
+			action.Warningf("Registry %q is not built-in, it is not used for pull requests.", r.Name)
+			continue
+		}
+
+		if !slices.Equal(r.Repositories, builtin[i].Repositories) || r.Prefix != builtin[i].Prefix {
+			action.Warningf("Registry %q configuration is not used for pull requests, built-in one is used instead.", r.Name)
+		}
+
+		res = append(res, builtin[i])
+	}
+
+	if len(res) == 0 && len(configured) > 0 {
+		action.Warningf("None of the configured registries are built-in, no images are published for pull requests.")
+	}
+
+	return res
+}
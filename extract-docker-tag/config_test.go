@@ -0,0 +1,256 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package extractdockertag
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sethvargo/go-githubactions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/github-actions/internal/testutil"
+)
+
+func TestParseConfig(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Default", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := defaultConfig()
+		assert.Equal(t, []string{"main", "releases/*"}, cfg.Branches)
+		assert.Len(t, cfg.Registries, 3)
+		assert.Len(t, cfg.Families, 3)
+	})
+
+	cases := []struct {
+		name string
+		file string
+		err  string
+	}{{
+		name: "Empty",
+		file: "",
+		err:  "configuration is empty",
+	}, {
+		name: "NoVersion",
+		file: "families: []",
+		err:  "unsupported version 0, expected 1",
+	}, {
+		name: "UnknownField",
+		file: "version: 1\nfamilies:\n  - output: development\n    name: foo\n",
+		err:  "yaml: unmarshal errors:\n  line 4: field name not found in type extractdockertag.familyConfig",
+	}, {
+		name: "NoFamilies",
+		file: "version: 1\n",
+		err:  "families: must be set",
+	}, {
+		name: "Output",
+		file: "version: 1\nfamilies:\n  - output: nightly\n    image: foo\n",
+		err:  `families[0].output: unexpected value "nightly", expected one of: all_in_one, development, production`,
+	}, {
+		name: "Ref",
+		file: "version: 1\nfamilies:\n  - output: development\n    image: foo\n    refs: [release]\n",
+		err:  `families[0].refs[0]: unexpected value "release", expected one of: pull_request, branch, tag, merge_group`,
+	}, {
+		name: "Registry",
+		file: "version: 1\nfamilies:\n  - output: development\n    image: foo\n    registries: [ghcr]\n",
+		err:  `families[0].registries[0]: unknown registry "ghcr"`,
+	}, {
+		name: "DuplicateRegistry",
+		file: "version: 1\nregistries:\n  - name: ghcr\n  - name: ghcr\n",
+		err:  `registries[1].name: duplicate name "ghcr"`,
+	}, {
+		name: "Floating",
+		file: "version: 1\nfamilies:\n  - output: development\n    image: foo\n" +
+			"tags:\n  - ref: tag\n    template: latest\n    floating: patch\n",
+		err: `tags[0].floating: unexpected value "patch", expected one of: all, major, minor`,
+	}, {
+		name: "Placeholder",
+		file: "version: 1\nfamilies:\n  - output: development\n    image: foo\ntags:\n  - ref: tag\n    template: \"{date}\"\n",
+		err:  `tags[0].template: unknown placeholder "{date}", expected one of: owner, name, branch, version, major, minor, patch, prerelease, sha`, //nolint:lll // for readability
+	}}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := parseConfig([]byte(tc.file))
+			require.Error(t, err)
+			assert.Equal(t, tc.err, err.Error())
+		})
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	custom := `
+version: 1
+branches: [main]
+registries:
+  - name: docker-hub
+    prefix: "{owner}/"
+families:
+  - output: production
+    image: "{name}"
+    refs: [branch, tag]
+tags:
+  - ref: branch
+    template: "{branch}-latest"
+  - ref: tag
+    template: "{major}.{minor}"
+    stable: true
+`[1:]
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "custom.yml"), []byte(custom), 0o666))
+
+	// the PR tries to publish images of any repository to Quay.io and to add a registry
+	untrusted := `
+version: 1
+registries:
+  - name: ghcr
+    prefix: ghcr.io/{owner}/
+  - name: quay
+    prefix: quay.io/{owner}/
+    repositories: ["*"]
+  - name: other
+    prefix: other.io/{owner}/
+families:
+  - output: development
+    image: "{name}-dev"
+    refs: [pull_request]
+tags:
+  - ref: pull_request
+    template: pr-{branch}
+`[1:]
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "untrusted.yml"), []byte(untrusted), 0o666))
+
+	customRegistries := `
+version: 1
+registries:
+  - name: ecr
+    prefix: 123456789012.dkr.ecr.us-east-1.amazonaws.com/{owner}/
+families:
+  - output: development
+    image: "{name}-dev"
+    refs: [pull_request]
+tags:
+  - ref: pull_request
+    template: pr-{branch}
+`[1:]
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "custom-registries.yml"), []byte(customRegistries), 0o666))
+
+	t.Run("Custom", func(t *testing.T) {
+		t.Parallel()
+
+		getenv := testutil.GetEnvFunc(t, map[string]string{
+			"GITHUB_EVENT_NAME": "push",
+			"GITHUB_WORKSPACE":  dir,
+			"INPUT_CONFIG":      "custom.yml",
+		})
+
+		cfg, err := loadConfig(githubactions.New(githubactions.WithGetenv(getenv)))
+		require.NoError(t, err)
+
+		getenv = testutil.GetEnvFunc(t, map[string]string{
+			"GITHUB_EVENT_NAME": "push",
+			"GITHUB_REF_NAME":   "v1.2.3",
+			"GITHUB_REF_TYPE":   "tag",
+			"GITHUB_REPOSITORY": "AlekSi/Project",
+		})
+
+		actual, _, err := extract(cfg, getenv, nil)
+		require.NoError(t, err)
+		assert.Equal(t, &result{productionImages: []string{"aleksi/project:1.2"}}, actual)
+
+		getenv = testutil.GetEnvFunc(t, map[string]string{
+			"GITHUB_EVENT_NAME": "push",
+			"GITHUB_REF_NAME":   "main",
+			"GITHUB_REF_TYPE":   "branch",
+			"GITHUB_REPOSITORY": "AlekSi/Project",
+		})
+
+		actual, _, err = extract(cfg, getenv, nil)
+		require.NoError(t, err)
+		assert.Equal(t, &result{productionImages: []string{"aleksi/project:main-latest"}}, actual)
+
+		getenv = testutil.GetEnvFunc(t, map[string]string{
+			"GITHUB_EVENT_NAME": "pull_request",
+			"GITHUB_HEAD_REF":   "feature",
+			"GITHUB_REPOSITORY": "AlekSi/Project",
+		})
+
+		actual, _, err = extract(cfg, getenv, nil)
+		require.NoError(t, err)
+		assert.Equal(t, new(result), actual)
+	})
+	t.Run("PullRequest", func(t *testing.T) {
+		t.Parallel()
+
+		getenv := testutil.GetEnvFunc(t, map[string]string{
+			"GITHUB_EVENT_NAME": "pull_request",
+			"GITHUB_WORKSPACE":  dir,
+			"INPUT_CONFIG":      "untrusted.yml",
+		})
+
+		var out bytes.Buffer
+		cfg, err := loadConfig(githubactions.New(githubactions.WithGetenv(getenv), githubactions.WithWriter(&out)))
+		require.NoError(t, err)
+		assert.Equal(t, defaultConfig().Registries[:2], cfg.Registries)
+		assert.Contains(t, out.String(), `::warning::Registry "quay" configuration is not used for pull requests`)
+		assert.Contains(t, out.String(), `::warning::Registry "other" is not built-in, it is not used for pull requests.`)
+
+		getenv = testutil.GetEnvFunc(t, map[string]string{
+			"GITHUB_EVENT_NAME": "pull_request",
+			"GITHUB_HEAD_REF":   "feature",
+			"GITHUB_REPOSITORY": "AlekSi/Project",
+		})
+
+		actual, _, err := extract(cfg, getenv, nil)
+		require.NoError(t, err)
+		assert.Equal(t, &result{developmentImages: []string{"ghcr.io/aleksi/project-dev:pr-feature"}}, actual)
+	})
+	t.Run("PullRequestCustomRegistries", func(t *testing.T) {
+		t.Parallel()
+
+		getenv := testutil.GetEnvFunc(t, map[string]string{
+			"GITHUB_EVENT_NAME": "pull_request",
+			"GITHUB_WORKSPACE":  dir,
+			"INPUT_CONFIG":      "custom-registries.yml",
+		})
+
+		var out bytes.Buffer
+		cfg, err := loadConfig(githubactions.New(githubactions.WithGetenv(getenv), githubactions.WithWriter(&out)))
+		require.NoError(t, err)
+		assert.Empty(t, cfg.Registries)
+		assert.Contains(t, out.String(), `::warning::Registry "ecr" is not built-in, it is not used for pull requests.`)
+		assert.Contains(t, out.String(), "::warning::None of the configured registries are built-in")
+
+		getenv = testutil.GetEnvFunc(t, map[string]string{
+			"GITHUB_EVENT_NAME": "pull_request",
+			"GITHUB_HEAD_REF":   "feature",
+			"GITHUB_REPOSITORY": "AlekSi/Project",
+		})
+
+		actual, _, err := extract(cfg, getenv, nil)
+		require.NoError(t, err)
+		assert.Equal(t, new(result), actual)
+	})
+}
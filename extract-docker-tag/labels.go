@@ -0,0 +1,139 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package extractdockertag
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sethvargo/go-githubactions"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// OCI image labels (also used as annotations).
+//
+// See https://github.com/opencontainers/image-spec/blob/main/annotations.md#pre-defined-annotation-keys.
+const (
+	labelCreated     = "org.opencontainers.image.created"
+	labelDescription = "org.opencontainers.image.description"
+	labelLicenses    = "org.opencontainers.image.licenses"
+	labelRevision    = "org.opencontainers.image.revision"
+	labelSource      = "org.opencontainers.image.source"
+	labelTitle       = "org.opencontainers.image.title"
+	labelURL         = "org.opencontainers.image.url"
+	labelVersion     = "org.opencontainers.image.version"
+)
+
+// ociLabels returns OCI image labels for the given ref and repository in environment variables.
+//
+// Empty labels are not returned.
+func ociLabels(info *refInfo, getenv githubactions.GetenvFunc, now time.Time) (map[string]string, error) {
+	rev, err := revision(info, getenv)
+	if err != nil {
+		return nil, fmt.Errorf("ociLabels: %w", err)
+	}
+
+	fullName := getenv("GITHUB_REPOSITORY")
+	_, name, _ := strings.Cut(fullName, "/")
+
+	serverURL := getenv("GITHUB_SERVER_URL")
+	if serverURL == "" {
+		serverURL = "https://github.com"
+	}
+
+	source := serverURL + "/" + fullName
+
+	var version string
+	if info.kind == refTag {
+		version = info.values["version"]
+	} else if len(info.tags) > 0 {
+		version = info.tags[0]
+	}
+
+	res := map[string]string{
+		labelCreated:  now.UTC().Format(time.RFC3339),
+		labelRevision: rev,
+		labelSource:   source,
+		labelTitle:    name,
+		labelURL:      source,
+		labelVersion:  version,
+	}
+
+	// description, license, and homepage are available only in the event payload
+	if info.event != nil {
+		repo := info.event.Repository()
+		res[labelDescription] = repo.GetDescription()
+		res[labelLicenses] = repo.GetLicense().GetSPDXID()
+
+		if u := repo.GetHomepage(); u != "" {
+			res[labelURL] = u
+		}
+	}
+
+	for k, v := range res {
+		// values are used in newline-separated outputs
+		v = strings.Join(strings.Fields(v), " ")
+
+		// GitHub uses NOASSERTION for unrecognized licenses
+		if v == "" || (k == labelLicenses && v == "NOASSERTION") {
+			delete(res, k)
+			continue
+		}
+
+		res[k] = v
+	}
+
+	return res, nil
+}
+
+// revision returns the commit SHA images are built from.
+//
+// For tags, that's the tag's commit in the GITHUB_WORKSPACE repository:
+// GITHUB_SHA is a commit of the branch that runs the workflow for `workflow_dispatch` event with `tag` input,
+// and the event payload may contain the annotated tag's object SHA.
+// For other refs, that's the event's head SHA, like PR's head.
+func revision(info *refInfo, getenv githubactions.GetenvFunc) (string, error) {
+	if info.kind == refTag {
+		dir := getenv("GITHUB_WORKSPACE")
+
+		b, err := git(dir, "rev-parse", "--verify", "refs/tags/"+info.name+"^{commit}")
+		if err != nil {
+			return "", fmt.Errorf("revision: git tag %q is not found in %s; check it out to build images: %w", info.name, dir, err)
+		}
+
+		return strings.TrimSpace(string(b)), nil
+	}
+
+	if info.event != nil {
+		return info.event.HeadSHA(), nil
+	}
+
+	return getenv("GITHUB_SHA"), nil
+}
+
+// formatLabels returns labels in `key=value` format, one per line, sorted by key.
+func formatLabels(labels map[string]string) string {
+	keys := maps.Keys(labels)
+	slices.Sort(keys)
+
+	lines := make([]string, len(keys))
+	for i, k := range keys {
+		lines[i] = k + "=" + labels[k]
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,205 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package extractdockertag
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/github-actions/internal"
+	"github.com/FerretDB/github-actions/internal/testutil"
+)
+
+func TestOCILabels(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 3, 14, 15, 9, 26, 0, time.FixedZone("MSK", 3*60*60))
+
+	// annotated tag is not on the checked out branch's head
+	dir := t.TempDir()
+	commit := []string{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "--allow-empty"}
+	runGit(t, dir, [][]string{
+		{"init", "--quiet"},
+		append(commit, "-m", "Release"),
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "tag", "-a", "-m", "Release", "v2.1.0-beta"},
+		append(commit, "-m", "Next"),
+	}...)
+
+	b, err := git(dir, "rev-parse", "HEAD~1")
+	require.NoError(t, err)
+	tagCommit := strings.TrimSpace(string(b))
+
+	b, err = git(dir, "rev-parse", "HEAD")
+	require.NoError(t, err)
+	headCommit := strings.TrimSpace(string(b))
+
+	cases := []struct {
+		name     string
+		env      map[string]string
+		event    string // payload file in testdata
+		expected map[string]string
+		err      string
+	}{{
+		name: "Tag",
+		env: map[string]string{
+			"GITHUB_EVENT_NAME": "push",
+			"GITHUB_REF_NAME":   "v2.1.0-beta",
+			"GITHUB_REF_TYPE":   "tag",
+			"GITHUB_REPOSITORY": "FerretDB/FerretDB",
+			"GITHUB_SERVER_URL": "https://github.com",
+			"GITHUB_WORKSPACE":  dir,
+		},
+		event: "push.json",
+		expected: map[string]string{
+			"org.opencontainers.image.created":     "2024-03-14T12:09:26Z",
+			"org.opencontainers.image.description": "A truly Open Source MongoDB alternative",
+			"org.opencontainers.image.licenses":    "Apache-2.0",
+			"org.opencontainers.image.revision":    tagCommit,
+			"org.opencontainers.image.source":      "https://github.com/FerretDB/FerretDB",
+			"org.opencontainers.image.title":       "FerretDB",
+			"org.opencontainers.image.url":         "https://www.ferretdb.io",
+			"org.opencontainers.image.version":     "2.1.0-beta",
+		},
+	}, {
+		name: "WorkflowDispatchTag",
+		env: map[string]string{
+			"GITHUB_EVENT_NAME": "workflow_dispatch",
+			"GITHUB_REF_NAME":   "main",
+			"GITHUB_REF_TYPE":   "branch",
+			"GITHUB_REPOSITORY": "FerretDB/FerretDB",
+			"GITHUB_SERVER_URL": "https://github.com",
+			"GITHUB_WORKSPACE":  dir,
+			"INPUT_TAG":         "v2.1.0-beta",
+		},
+		expected: map[string]string{
+			"org.opencontainers.image.created":  "2024-03-14T12:09:26Z",
+			"org.opencontainers.image.revision": tagCommit,
+			"org.opencontainers.image.source":   "https://github.com/FerretDB/FerretDB",
+			"org.opencontainers.image.title":    "FerretDB",
+			"org.opencontainers.image.url":      "https://github.com/FerretDB/FerretDB",
+			"org.opencontainers.image.version":  "2.1.0-beta",
+		},
+	}, {
+		name: "TagNotFound",
+		env: map[string]string{
+			"GITHUB_EVENT_NAME": "workflow_dispatch",
+			"GITHUB_REF_NAME":   "main",
+			"GITHUB_REF_TYPE":   "branch",
+			"GITHUB_REPOSITORY": "FerretDB/FerretDB",
+			"GITHUB_WORKSPACE":  dir,
+			"INPUT_TAG":         "v2.2.0",
+		},
+		err: `ociLabels: revision: git tag "v2.2.0" is not found in ` + dir,
+	}, {
+		name: "Branch",
+		env: map[string]string{
+			"GITHUB_EVENT_NAME": "push",
+			"GITHUB_REF_NAME":   "main",
+			"GITHUB_REF_TYPE":   "branch",
+			"GITHUB_REPOSITORY": "FerretDB/FerretDB",
+			"GITHUB_SERVER_URL": "https://github.com",
+			"GITHUB_SHA":        headCommit,
+		},
+		expected: map[string]string{
+			"org.opencontainers.image.created":  "2024-03-14T12:09:26Z",
+			"org.opencontainers.image.revision": headCommit,
+			"org.opencontainers.image.source":   "https://github.com/FerretDB/FerretDB",
+			"org.opencontainers.image.title":    "FerretDB",
+			"org.opencontainers.image.url":      "https://github.com/FerretDB/FerretDB",
+			"org.opencontainers.image.version":  "main",
+		},
+	}, {
+		name: "PullRequestHead",
+		env: map[string]string{
+			"GITHUB_EVENT_NAME": "pull_request",
+			"GITHUB_HEAD_REF":   "dependabot/go_modules/tools/github.com/reviewdog/reviewdog-0.14.0",
+			"GITHUB_REPOSITORY": "AlekSi/FerretDB",
+			"GITHUB_SERVER_URL": "https://github.com",
+		},
+		event: "pull_request_dependabot.json",
+		expected: map[string]string{
+			"org.opencontainers.image.created":     "2024-03-14T12:09:26Z",
+			"org.opencontainers.image.description": "A truly Open Source MongoDB alternative",
+			"org.opencontainers.image.licenses":    "Apache-2.0",
+			"org.opencontainers.image.revision":    "f1c766da9d76d388cef27e97baf2416c7f12d3e4",
+			"org.opencontainers.image.source":      "https://github.com/AlekSi/FerretDB",
+			"org.opencontainers.image.title":       "FerretDB",
+			"org.opencontainers.image.url":         "https://www.ferretdb.io",
+			"org.opencontainers.image.version":     "pr-reviewdog-0.14.0",
+		},
+	}, {
+		name: "PullRequest",
+		env: map[string]string{
+			"GITHUB_EVENT_NAME": "pull_request",
+			"GITHUB_HEAD_REF":   "dependabot/submodules/tests/mongo-go-driver-29d768e",
+			"GITHUB_REPOSITORY": "FerretDB/some-repo",
+			"GITHUB_SERVER_URL": "",
+			"GITHUB_SHA":        "",
+		},
+		expected: map[string]string{
+			"org.opencontainers.image.created": "2024-03-14T12:09:26Z",
+			"org.opencontainers.image.source":  "https://github.com/FerretDB/some-repo",
+			"org.opencontainers.image.title":   "some-repo",
+			"org.opencontainers.image.url":     "https://github.com/FerretDB/some-repo",
+			"org.opencontainers.image.version": "pr-mongo-go-driver-29d768e",
+		},
+	}}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			getenv := testutil.GetEnvFunc(t, tc.env)
+
+			var event *internal.Event
+			if tc.event != "" {
+				event = readEvent(t, tc.env["GITHUB_EVENT_NAME"], filepath.Join("..", "testdata", tc.event))
+			}
+
+			_, info, err := extract(defaultConfig(), getenv, event)
+			require.NoError(t, err)
+
+			actual, err := ociLabels(info, getenv, now)
+			if tc.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestFormatLabels(t *testing.T) {
+	t.Parallel()
+
+	labels := map[string]string{
+		"org.opencontainers.image.title":   "FerretDB",
+		"org.opencontainers.image.version": "2.1.0",
+		"org.opencontainers.image.created": "2024-03-14T12:09:26Z",
+	}
+
+	expected := "org.opencontainers.image.created=2024-03-14T12:09:26Z\n" +
+		"org.opencontainers.image.title=FerretDB\n" +
+		"org.opencontainers.image.version=2.1.0"
+	assert.Equal(t, expected, formatLabels(labels))
+}
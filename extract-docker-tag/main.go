@@ -12,40 +12,99 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package main
+// Package extractdockertag implements `extract-docker-tag` action.
+package extractdockertag
 
 import (
-	"flag"
+	"context"
 	"fmt"
-	"regexp"
 	"sort"
+	"strconv"
 	"strings"
-	"text/tabwriter"
+	"time"
 
+	"github.com/google/go-github/v70/github"
 	"github.com/sethvargo/go-githubactions"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
 
 	"github.com/FerretDB/github-actions/internal"
+	"github.com/FerretDB/github-actions/internal/output"
 )
 
-func main() {
-	flag.Parse()
+// Run runs the action.
+func Run(_ context.Context, action *githubactions.Action) error {
+	internal.DebugEnv(action)
 
-	action := githubactions.New()
+	cfg, err := loadConfig(action)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
 
-	internal.DebugEnv(action)
+	event, err := internal.ReadEvent(action)
+	if err != nil {
+		return fmt.Errorf("failed to read event: %w", err)
+	}
 
-	result, err := extract(action.Getenv)
+	result, info, err := extract(cfg, action.Getenv, event)
 	if err != nil {
-		action.Fatalf("%s", err)
+		return err
+	}
+
+	if result.labels, err = ociLabels(info, action.Getenv, time.Now()); err != nil {
+		return fmt.Errorf("failed to get OCI labels: %w", err)
 	}
 
-	setResults(action, result)
+	return setResults(action, result)
 }
 
 type result struct {
 	allInOneImages    []string
 	developmentImages []string
 	productionImages  []string
+
+	// OCI image labels and annotations
+	labels map[string]string
+}
+
+// resultJSON is a result encoded as `json` output.
+type resultJSON struct {
+	AllInOneImages    []string          `json:"all_in_one_images"`
+	DevelopmentImages []string          `json:"development_images"`
+	ProductionImages  []string          `json:"production_images"`
+	Labels            map[string]string `json:"labels,omitempty"`
+}
+
+// json returns result for `json` output; images are never null.
+func (r *result) json() *resultJSON {
+	nonNil := func(s []string) []string {
+		if s == nil {
+			return []string{}
+		}
+
+		return s
+	}
+
+	return &resultJSON{
+		AllInOneImages:    nonNil(r.allInOneImages),
+		DevelopmentImages: nonNil(r.developmentImages),
+		ProductionImages:  nonNil(r.productionImages),
+		Labels:            r.labels,
+	}
+}
+
+// add adds image to the given family's output.
+func (r *result) add(output, image string) {
+	switch output {
+	case outputAllInOne:
+		r.allInOneImages = append(r.allInOneImages, image)
+	case outputDevelopment:
+		r.developmentImages = append(r.developmentImages, image)
+	case outputProduction:
+		r.productionImages = append(r.productionImages, image)
+	default:
+		panic(fmt.Sprintf("unexpected output %q", output))
+	}
 }
 
 // Sort sorts all images in-place.
@@ -55,212 +114,333 @@ func (r *result) Sort() {
 	sort.Strings(r.productionImages)
 }
 
-// https://semver.org/#is-there-a-suggested-regular-expression-regex-to-check-a-semver-string,
-// but with leading `v`.
-var semVerTag = regexp.MustCompile(`^v(?P<major>0|[1-9]\d*)\.(?P<minor>0|[1-9]\d*)\.(?P<patch>0|[1-9]\d*)(?:-(?P<prerelease>(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?(?:\+(?P<buildmetadata>[0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`)
+// refInfo describes the ref images are built for.
+type refInfo struct {
+	kind   string            // one of knownRefs
+	name   string            // ref name as is, for error messages
+	values map[string]string // placeholder values
+	stable bool              // true for versions without prerelease part
+
+	version    *semVer   // for tags only
+	precedence bool      // true if precedence of version is checked
+	existing   []*semVer // versions of existing git tags, including version; only if precedence is checked
 
-//nolint:goconst // "ferretdb" means different things
-func extract(getenv githubactions.GetenvFunc) (*result, error) {
+	event *internal.Event // nil if payload is not available
+	tags  []string        // Docker tags for that ref
+}
+
+// extract returns images for the given event and repository in environment variables,
+// and information about the ref they are built for.
+//
+// Event payload is required only for some events; it could be nil for others.
+func extract(cfg *config, getenv githubactions.GetenvFunc, event *internal.Event) (*result, *refInfo, error) {
+	info, err := parseRef(cfg, getenv, event)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if info.tags, err = cfg.tags(info); err != nil {
+		return nil, nil, err
+	}
+
+	res, err := cfg.images(info)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	res.Sort()
+
+	return res, info, nil
+}
+
+// parseRef returns information about the ref for the given event and repository in environment variables.
+func parseRef(cfg *config, getenv githubactions.GetenvFunc, event *internal.Event) (*refInfo, error) {
 	// extract owner and name to support GitHub forks
 	parts := strings.Split(strings.ToLower(getenv("GITHUB_REPOSITORY")), "/")
 	if len(parts) != 2 {
 		return nil, fmt.Errorf("failed to extract owner or name")
 	}
-	owner := parts[0]
-	name := parts[1]
+
+	values := map[string]string{
+		"owner": parts[0],
+		"name":  parts[1],
+	}
+
+	var ref, refName string
+	var version *semVer
+	var prerelease bool
 
 	// extract tags for various events
-	event := getenv("GITHUB_EVENT_NAME")
-	switch event {
+	eventName := getenv("GITHUB_EVENT_NAME")
+	switch eventName {
 	case "pull_request", "pull_request_target":
 		// for branches like "dependabot/submodules/XXX"
-		branch := strings.ToLower(getenv("GITHUB_HEAD_REF"))
-		parts = strings.Split(branch, "/")
-		branch = parts[len(parts)-1]
-
-		res := &result{
-			developmentImages: []string{
-				fmt.Sprintf("ghcr.io/%s/%s-dev:pr-%s", owner, name, branch),
-			},
-		}
+		refName = getenv("GITHUB_HEAD_REF")
+		parts = strings.Split(strings.ToLower(refName), "/")
 
-		// all-in-one only for FerretDB
-		if name == "ferretdb" {
-			res.allInOneImages = append(res.allInOneImages, fmt.Sprintf("ghcr.io/%s/all-in-one:pr-%s", owner, branch))
+		ref = refPullRequest
+		if values["branch"] = sanitizeBranch(parts[len(parts)-1]); values["branch"] == "" {
+			return nil, fmt.Errorf("no valid Docker tag for branch %q", refName)
+		}
 
-			// no forks, no other repos for Quay.io and Docker Hub
-			if owner == "ferretdb" {
-				res.allInOneImages = append(res.allInOneImages, fmt.Sprintf("quay.io/ferretdb/all-in-one:pr-%s", branch))
-				res.developmentImages = append(res.developmentImages, fmt.Sprintf("quay.io/ferretdb/ferretdb-dev:pr-%s", branch))
+	case "push", "schedule", "workflow_run", "workflow_dispatch":
+		refType := strings.ToLower(getenv("GITHUB_REF_TYPE"))
+		refName = getenv("GITHUB_REF_NAME")
 
-				res.allInOneImages = append(res.allInOneImages, fmt.Sprintf("ferretdb/all-in-one:pr-%s", branch))
-				res.developmentImages = append(res.developmentImages, fmt.Sprintf("ferretdb/ferretdb-dev:pr-%s", branch))
+		// manually run workflow could build the given tag instead of the ref it runs on
+		if eventName == "workflow_dispatch" {
+			if tag := getenv("INPUT_TAG"); tag != "" {
+				refType, refName = "tag", tag
 			}
 		}
 
-		res.Sort()
-		return res, nil
-
-	case "push", "schedule", "workflow_run":
-		refType := strings.ToLower(getenv("GITHUB_REF_TYPE"))
-		refName := strings.ToLower(getenv("GITHUB_REF_NAME"))
-
 		switch refType {
 		case "branch":
 			// build on pull_request/pull_request_target for other branches
-			switch {
-			case refName == "main":
-				// nothing
-			case strings.HasPrefix(refName, "releases/"):
-				refName = strings.ReplaceAll(refName, "/", "-")
-			default:
-				return nil, fmt.Errorf("unhandled branch %q", refName)
+			if !matches(cfg.Branches, refName) {
+				return nil, fmt.Errorf("unhandled branch %q", strings.ToLower(refName))
 			}
 
-			res := &result{
-				developmentImages: []string{
-					fmt.Sprintf("ghcr.io/%s/%s-dev:%s", owner, name, refName),
-				},
+			ref = refBranch
+			if values["branch"] = sanitizeBranch(strings.ReplaceAll(strings.ToLower(refName), "/", "-")); values["branch"] == "" {
+				return nil, fmt.Errorf("no valid Docker tag for branch %q", refName)
 			}
 
-			// all-in-one only for FerretDB
-			if name == "ferretdb" {
-				res.allInOneImages = append(res.allInOneImages, fmt.Sprintf("ghcr.io/%s/all-in-one:%s", owner, refName))
+		case "tag":
+			var err error
+			if version, err = parseTag(strings.ToLower(refName), values); err != nil {
+				return nil, err
+			}
 
-				// no forks, no other repos for Quay.io and Docker Hub
-				if owner == "ferretdb" {
-					res.allInOneImages = append(res.allInOneImages, fmt.Sprintf("quay.io/ferretdb/all-in-one:%s", refName))
-					res.developmentImages = append(res.developmentImages, fmt.Sprintf("quay.io/ferretdb/ferretdb-dev:%s", refName))
+			ref = refTag
 
-					res.allInOneImages = append(res.allInOneImages, fmt.Sprintf("ferretdb/all-in-one:%s", refName))
-					res.developmentImages = append(res.developmentImages, fmt.Sprintf("ferretdb/ferretdb-dev:%s", refName))
-				}
-			}
+		default:
+			return nil, fmt.Errorf("unhandled ref type %q for event %q", refType, eventName)
+		}
+
+	case "release":
+		payload, err := eventPayload[*github.ReleaseEvent](event)
+		if err != nil {
+			return nil, err
+		}
 
-			res.Sort()
-			return res, nil
+		// draft releases do not have tags yet
+		action := payload.GetAction()
+		if action != "published" && action != "prereleased" {
+			return nil, fmt.Errorf("unhandled release action %q", action)
+		}
 
-		case "tag":
-			// extract version from git tag
-			match := semVerTag.FindStringSubmatch(refName)
-			if match == nil || len(match) != semVerTag.NumSubexp()+1 {
-				return nil, fmt.Errorf("unexpected git tag %q", refName)
-			}
-			major := match[semVerTag.SubexpIndex("major")]
-			minor := match[semVerTag.SubexpIndex("minor")]
-			patch := match[semVerTag.SubexpIndex("patch")]
-			prerelease := match[semVerTag.SubexpIndex("prerelease")]
-
-			version := major + "." + minor + "." + patch
-			if prerelease != "" {
-				version += "-" + prerelease
-			}
+		refName = payload.GetRelease().GetTagName()
 
-			res := &result{
-				developmentImages: []string{
-					fmt.Sprintf("ghcr.io/%s/%s-dev:%s", owner, name, version),
-				},
-				productionImages: []string{
-					fmt.Sprintf("ghcr.io/%s/%s:%s", owner, name, version),
-				},
-			}
+		if version, err = parseTag(strings.ToLower(refName), values); err != nil {
+			return nil, err
+		}
 
-			// all-in-one only for FerretDB
-			if name == "ferretdb" {
-				res.allInOneImages = append(res.allInOneImages, fmt.Sprintf("ghcr.io/%s/all-in-one:%s", owner, version))
+		// do not move floating tags like `latest` to releases marked as prereleases
+		prerelease = payload.GetRelease().GetPrerelease()
 
-				// no forks, no other repos for Quay.io and Docker Hub
-				if owner == "ferretdb" {
-					res.allInOneImages = append(res.allInOneImages, fmt.Sprintf("quay.io/ferretdb/all-in-one:%s", version))
-					res.developmentImages = append(res.developmentImages, fmt.Sprintf("quay.io/ferretdb/ferretdb-dev:%s", version))
-					res.productionImages = append(res.productionImages, fmt.Sprintf("quay.io/ferretdb/ferretdb:%s", version))
+		ref = refTag
 
-					res.allInOneImages = append(res.allInOneImages, fmt.Sprintf("ferretdb/all-in-one:%s", version))
-					res.developmentImages = append(res.developmentImages, fmt.Sprintf("ferretdb/ferretdb-dev:%s", version))
-					res.productionImages = append(res.productionImages, fmt.Sprintf("ferretdb/ferretdb:%s", version))
-				}
+	case "merge_group":
+		payload, err := eventPayload[*github.MergeGroupEvent](event)
+		if err != nil {
+			return nil, err
+		}
+
+		sha := strings.ToLower(payload.GetMergeGroup().GetHeadSHA())
+		if sha == "" {
+			return nil, fmt.Errorf("no head SHA for merge group")
+		}
+
+		ref = refMergeGroup
+		refName = payload.GetMergeGroup().GetHeadRef()
+		values["sha"] = sha
+
+	default:
+		return nil, fmt.Errorf("unhandled event type %q", eventName)
+	}
+
+	info := &refInfo{
+		kind:    ref,
+		name:    refName,
+		values:  values,
+		version: version,
+		event:   event,
+	}
+
+	if version != nil {
+		info.stable = version.prerelease == "" && !prerelease
+
+		// there is no need to check precedence if floating tags are not used anyway
+		if cfg.CheckPrecedence && info.stable {
+			existing, err := existingVersions(getenv("GITHUB_WORKSPACE"), version)
+			if err != nil {
+				return nil, err
 			}
 
-			if prerelease == "" {
-				res.developmentImages = append(res.developmentImages, fmt.Sprintf("ghcr.io/%s/%s-dev:%s", owner, name, major))
-				res.productionImages = append(res.productionImages, fmt.Sprintf("ghcr.io/%s/%s:%s", owner, name, major))
+			info.precedence = true
+			info.existing = existing
+		}
+	}
+
+	return info, nil
+}
+
+// parseTag extracts version from the given git tag and sets placeholder values.
+func parseTag(tag string, values map[string]string) (*semVer, error) {
+	v, err := parseSemVer(tag)
+	if err != nil {
+		return nil, err
+	}
 
-				res.developmentImages = append(res.developmentImages, fmt.Sprintf("ghcr.io/%s/%s-dev:latest", owner, name))
-				res.productionImages = append(res.productionImages, fmt.Sprintf("ghcr.io/%s/%s:latest", owner, name))
+	values["major"] = strconv.FormatUint(v.major, 10)
+	values["minor"] = strconv.FormatUint(v.minor, 10)
+	values["patch"] = strconv.FormatUint(v.patch, 10)
+	values["prerelease"] = v.prerelease
+	values["version"] = v.String()
 
-				// all-in-one only for FerretDB
-				if name == "ferretdb" {
-					res.allInOneImages = append(res.allInOneImages, fmt.Sprintf("ghcr.io/%s/all-in-one:%s", owner, major))
+	return v, nil
+}
 
-					res.allInOneImages = append(res.allInOneImages, fmt.Sprintf("ghcr.io/%s/all-in-one:latest", owner))
+// eventPayload returns event payload of the given type.
+func eventPayload[T any](event *internal.Event) (T, error) {
+	var zero T
 
-					// no forks, no other repos for Quay.io and Docker Hub
-					//nolint:lll // for readibility
-					if owner == "ferretdb" {
-						res.allInOneImages = append(res.allInOneImages, fmt.Sprintf("quay.io/ferretdb/all-in-one:%s", major))
-						res.developmentImages = append(res.developmentImages, fmt.Sprintf("quay.io/ferretdb/ferretdb-dev:%s", major))
-						res.productionImages = append(res.productionImages, fmt.Sprintf("quay.io/ferretdb/ferretdb:%s", major))
+	if event == nil {
+		return zero, fmt.Errorf("event payload is not available")
+	}
 
-						res.allInOneImages = append(res.allInOneImages, "quay.io/ferretdb/all-in-one:latest")
-						res.developmentImages = append(res.developmentImages, "quay.io/ferretdb/ferretdb-dev:latest")
-						res.productionImages = append(res.productionImages, "quay.io/ferretdb/ferretdb:latest")
+	payload, ok := event.Payload.(T)
+	if !ok {
+		return zero, fmt.Errorf("unexpected payload %T for event %q", event.Payload, event.Name)
+	}
 
-						res.allInOneImages = append(res.allInOneImages, fmt.Sprintf("ferretdb/all-in-one:%s", major))
-						res.developmentImages = append(res.developmentImages, fmt.Sprintf("ferretdb/ferretdb-dev:%s", major))
-						res.productionImages = append(res.productionImages, fmt.Sprintf("ferretdb/ferretdb:%s", major))
+	return payload, nil
+}
 
-						res.allInOneImages = append(res.allInOneImages, "ferretdb/all-in-one:latest")
-						res.developmentImages = append(res.developmentImages, "ferretdb/ferretdb-dev:latest")
-						res.productionImages = append(res.productionImages, "ferretdb/ferretdb:latest")
-					}
-				}
-			}
+// tags returns valid Docker image tags for the given ref.
+//
+// Branch names are sanitized by extract, so different branches produce different tags;
+// expanded templates are only shortened if needed.
+func (c *config) tags(info *refInfo) ([]string, error) {
+	var res []string
+
+	for _, t := range c.Tags {
+		if t.Ref != info.kind || (t.Stable && !info.stable) {
+			continue
+		}
 
-			res.Sort()
-			return res, nil
+		// do not move floating tags to older versions, like patch releases from release branches
+		if t.Floating != "" && info.precedence && !isHighest(info.version, info.existing, t.Floating) {
+			continue
+		}
 
-		default:
-			return nil, fmt.Errorf("unhandled ref type %q for event %q", refType, event)
+		tag := sanitizeTag(expand(t.Template, info.values))
+		if tag == "" {
+			return nil, fmt.Errorf("no valid Docker tag for %s %q with template %q", info.kind, info.name, t.Template)
 		}
 
-	default:
-		return nil, fmt.Errorf("unhandled event type %q", event)
+		res = append(res, tag)
 	}
+
+	return res, nil
+}
+
+// images returns images of all families for the given ref and its tags.
+func (c *config) images(info *refInfo) (*result, error) {
+	values := info.values
+	repo := values["owner"] + "/" + values["name"]
+
+	res := new(result)
+
+	for _, f := range c.Families {
+		if !slices.Contains(f.Refs, info.kind) {
+			continue
+		}
+
+		if len(f.Repositories) > 0 && !matches(f.Repositories, repo) {
+			continue
+		}
+
+		image := expand(f.Image, values)
+
+		for _, r := range c.Registries {
+			if len(f.Registries) > 0 && !slices.Contains(f.Registries, r.Name) {
+				continue
+			}
+
+			if len(r.Repositories) > 0 && !matches(r.Repositories, repo) {
+				continue
+			}
+
+			name := expand(r.Prefix, values) + image
+			if err := validateImageName(name); err != nil {
+				return nil, fmt.Errorf("invalid image name %q for repository %q and %s %q: %w", name, repo, info.kind, info.name, err)
+			}
+
+			for _, tag := range info.tags {
+				res.add(f.Output, name+":"+tag)
+			}
+		}
+	}
+
+	return res, nil
 }
 
 // setResults sets action output parameters, summary, etc.
-func setResults(action *githubactions.Action, result *result) {
-	var buf strings.Builder
-	w := tabwriter.NewWriter(&buf, 1, 1, 1, ' ', tabwriter.Debug)
-	fmt.Fprintf(w, "\tType\tImage\t\n")
-	fmt.Fprintf(w, "\t----\t-----\t\n")
+func setResults(action *githubactions.Action, result *result) error {
+	table := output.NewTable("Type", "Image")
 
 	for _, image := range result.allInOneImages {
 		u := imageURL(image)
 		action.Noticef("All-in-one: %s (see %s)", image, u)
-		fmt.Fprintf(w, "\tAll-in-one\t[`%s`](%s)\t\n", image, u)
+		table.Add("All-in-one", fmt.Sprintf("[`%s`](%s)", image, u))
 	}
 
 	for _, image := range result.developmentImages {
 		u := imageURL(image)
 		action.Noticef("Development: %s (see %s)", image, u)
-		fmt.Fprintf(w, "\tDevelopment\t[`%s`](%s)\t\n", image, u)
+		table.Add("Development", fmt.Sprintf("[`%s`](%s)", image, u))
 	}
 
 	for _, image := range result.productionImages {
 		u := imageURL(image)
 		action.Noticef("Production: %s (see %s)", image, u)
-		fmt.Fprintf(w, "\tProduction\t[`%s`](%s)\t\n", image, u)
+		table.Add("Production", fmt.Sprintf("[`%s`](%s)", image, u))
 	}
 
-	w.Flush()
+	summary := table.String()
+
+	if len(result.labels) > 0 {
+		labels := output.NewTable("Label", "Value")
+
+		keys := maps.Keys(result.labels)
+		slices.Sort(keys)
+
+		for _, k := range keys {
+			labels.Add("`"+k+"`", result.labels[k])
+		}
+
+		summary += "\n" + output.Details("OCI labels", labels.String(), false)
+	}
 
-	action.AddStepSummary(buf.String())
-	action.Infof("%s", buf.String())
+	output.AddSummary(action, summary)
 
 	action.SetOutput("all_in_one_images", strings.Join(result.allInOneImages, ","))
 	action.SetOutput("development_images", strings.Join(result.developmentImages, ","))
 	action.SetOutput("production_images", strings.Join(result.productionImages, ","))
+
+	if len(result.labels) > 0 {
+		labels := formatLabels(result.labels)
+		action.SetOutput("labels", labels)
+		action.SetOutput("annotations", labels)
+	}
+
+	if err := output.SetResult(action, result.json()); err != nil {
+		return fmt.Errorf("setResults: %w", err)
+	}
+
+	return nil
 }
 
 // imageURL returns URL for the given image name.
@@ -12,12 +12,13 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package main
+package extractdockertag
 
 import (
 	"bytes"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -25,9 +26,29 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/FerretDB/github-actions/internal"
 	"github.com/FerretDB/github-actions/internal/testutil"
 )
 
+// readEvent reads the payload of the event with the given name from the given file or fails the test.
+func readEvent(t testing.TB, name, path string) *internal.Event {
+	t.Helper()
+
+	env := map[string]string{
+		"GITHUB_EVENT_NAME": name,
+		"GITHUB_EVENT_PATH": path,
+	}
+	action := githubactions.New(
+		githubactions.WithWriter(io.Discard),
+		githubactions.WithGetenv(func(key string) string { return env[key] }),
+	)
+
+	event, err := internal.ReadEvent(action)
+	require.NoError(t, err)
+
+	return event
+}
+
 func TestExtractFerretDB(t *testing.T) {
 	t.Run("pull_request", func(t *testing.T) {
 		getenv := testutil.GetEnvFunc(t, map[string]string{
@@ -39,7 +60,7 @@ func TestExtractFerretDB(t *testing.T) {
 			"GITHUB_REPOSITORY": "FerretDB/FerretDB",
 		})
 
-		actual, err := extract(getenv)
+		actual, _, err := extract(defaultConfig(), getenv, nil)
 		require.NoError(t, err)
 
 		expected := &result{
@@ -67,7 +88,7 @@ func TestExtractFerretDB(t *testing.T) {
 			"GITHUB_REPOSITORY": "FerretDB/FerretDB",
 		})
 
-		actual, err := extract(getenv)
+		actual, _, err := extract(defaultConfig(), getenv, nil)
 		require.NoError(t, err)
 
 		expected := &result{
@@ -95,7 +116,7 @@ func TestExtractFerretDB(t *testing.T) {
 			"GITHUB_REPOSITORY": "FerretDB/FerretDB",
 		})
 
-		actual, err := extract(getenv)
+		actual, _, err := extract(defaultConfig(), getenv, nil)
 		require.NoError(t, err)
 
 		expected := &result{
@@ -123,7 +144,7 @@ func TestExtractFerretDB(t *testing.T) {
 			"GITHUB_REPOSITORY": "FerretDB/FerretDB",
 		})
 
-		actual, err := extract(getenv)
+		actual, _, err := extract(defaultConfig(), getenv, nil)
 		require.NoError(t, err)
 
 		expected := &result{
@@ -151,7 +172,7 @@ func TestExtractFerretDB(t *testing.T) {
 			"GITHUB_REPOSITORY": "FerretDB/FerretDB",
 		})
 
-		actual, err := extract(getenv)
+		actual, _, err := extract(defaultConfig(), getenv, nil)
 		require.NoError(t, err)
 
 		expected := &result{
@@ -179,7 +200,7 @@ func TestExtractFerretDB(t *testing.T) {
 			"GITHUB_REPOSITORY": "FerretDB/FerretDB",
 		})
 
-		actual, err := extract(getenv)
+		actual, _, err := extract(defaultConfig(), getenv, nil)
 		require.NoError(t, err)
 
 		expected := &result{
@@ -212,40 +233,49 @@ func TestExtractFerretDB(t *testing.T) {
 			"GITHUB_REPOSITORY": "FerretDB/FerretDB",
 		})
 
-		actual, err := extract(getenv)
+		actual, _, err := extract(defaultConfig(), getenv, nil)
 		require.NoError(t, err)
 
 		expected := &result{
 			allInOneImages: []string{
 				"ferretdb/all-in-one:2",
+				"ferretdb/all-in-one:2.1",
 				"ferretdb/all-in-one:2.1.0",
 				"ferretdb/all-in-one:latest",
 				"ghcr.io/ferretdb/all-in-one:2",
+				"ghcr.io/ferretdb/all-in-one:2.1",
 				"ghcr.io/ferretdb/all-in-one:2.1.0",
 				"ghcr.io/ferretdb/all-in-one:latest",
 				"quay.io/ferretdb/all-in-one:2",
+				"quay.io/ferretdb/all-in-one:2.1",
 				"quay.io/ferretdb/all-in-one:2.1.0",
 				"quay.io/ferretdb/all-in-one:latest",
 			},
 			developmentImages: []string{
 				"ferretdb/ferretdb-dev:2",
+				"ferretdb/ferretdb-dev:2.1",
 				"ferretdb/ferretdb-dev:2.1.0",
 				"ferretdb/ferretdb-dev:latest",
 				"ghcr.io/ferretdb/ferretdb-dev:2",
+				"ghcr.io/ferretdb/ferretdb-dev:2.1",
 				"ghcr.io/ferretdb/ferretdb-dev:2.1.0",
 				"ghcr.io/ferretdb/ferretdb-dev:latest",
 				"quay.io/ferretdb/ferretdb-dev:2",
+				"quay.io/ferretdb/ferretdb-dev:2.1",
 				"quay.io/ferretdb/ferretdb-dev:2.1.0",
 				"quay.io/ferretdb/ferretdb-dev:latest",
 			},
 			productionImages: []string{
 				"ferretdb/ferretdb:2",
+				"ferretdb/ferretdb:2.1",
 				"ferretdb/ferretdb:2.1.0",
 				"ferretdb/ferretdb:latest",
 				"ghcr.io/ferretdb/ferretdb:2",
+				"ghcr.io/ferretdb/ferretdb:2.1",
 				"ghcr.io/ferretdb/ferretdb:2.1.0",
 				"ghcr.io/ferretdb/ferretdb:latest",
 				"quay.io/ferretdb/ferretdb:2",
+				"quay.io/ferretdb/ferretdb:2.1",
 				"quay.io/ferretdb/ferretdb:2.1.0",
 				"quay.io/ferretdb/ferretdb:latest",
 			},
@@ -263,7 +293,7 @@ func TestExtractFerretDB(t *testing.T) {
 			"GITHUB_REPOSITORY": "FerretDB/FerretDB",
 		})
 
-		_, err := extract(getenv)
+		_, _, err := extract(defaultConfig(), getenv, nil)
 		require.Error(t, err)
 	})
 
@@ -277,7 +307,7 @@ func TestExtractFerretDB(t *testing.T) {
 			"GITHUB_REPOSITORY": "FerretDB/FerretDB",
 		})
 
-		actual, err := extract(getenv)
+		actual, _, err := extract(defaultConfig(), getenv, nil)
 		require.NoError(t, err)
 
 		expected := &result{
@@ -305,7 +335,7 @@ func TestExtractFerretDB(t *testing.T) {
 			"GITHUB_REPOSITORY": "FerretDB/FerretDB",
 		})
 
-		actual, err := extract(getenv)
+		actual, _, err := extract(defaultConfig(), getenv, nil)
 		require.NoError(t, err)
 
 		expected := &result{
@@ -322,6 +352,210 @@ func TestExtractFerretDB(t *testing.T) {
 		}
 		assert.Equal(t, expected, actual)
 	})
+
+	t.Run("release/published", func(t *testing.T) {
+		getenv := testutil.GetEnvFunc(t, map[string]string{
+			"GITHUB_EVENT_NAME": "release",
+			"GITHUB_REPOSITORY": "FerretDB/FerretDB",
+		})
+
+		event := readEvent(t, "release", filepath.Join("..", "testdata", "release.json"))
+
+		actual, _, err := extract(defaultConfig(), getenv, event)
+		require.NoError(t, err)
+
+		expected := &result{
+			allInOneImages: []string{
+				"ferretdb/all-in-one:0",
+				"ferretdb/all-in-one:0.1",
+				"ferretdb/all-in-one:0.1.0",
+				"ferretdb/all-in-one:latest",
+				"ghcr.io/ferretdb/all-in-one:0",
+				"ghcr.io/ferretdb/all-in-one:0.1",
+				"ghcr.io/ferretdb/all-in-one:0.1.0",
+				"ghcr.io/ferretdb/all-in-one:latest",
+				"quay.io/ferretdb/all-in-one:0",
+				"quay.io/ferretdb/all-in-one:0.1",
+				"quay.io/ferretdb/all-in-one:0.1.0",
+				"quay.io/ferretdb/all-in-one:latest",
+			},
+			developmentImages: []string{
+				"ferretdb/ferretdb-dev:0",
+				"ferretdb/ferretdb-dev:0.1",
+				"ferretdb/ferretdb-dev:0.1.0",
+				"ferretdb/ferretdb-dev:latest",
+				"ghcr.io/ferretdb/ferretdb-dev:0",
+				"ghcr.io/ferretdb/ferretdb-dev:0.1",
+				"ghcr.io/ferretdb/ferretdb-dev:0.1.0",
+				"ghcr.io/ferretdb/ferretdb-dev:latest",
+				"quay.io/ferretdb/ferretdb-dev:0",
+				"quay.io/ferretdb/ferretdb-dev:0.1",
+				"quay.io/ferretdb/ferretdb-dev:0.1.0",
+				"quay.io/ferretdb/ferretdb-dev:latest",
+			},
+			productionImages: []string{
+				"ferretdb/ferretdb:0",
+				"ferretdb/ferretdb:0.1",
+				"ferretdb/ferretdb:0.1.0",
+				"ferretdb/ferretdb:latest",
+				"ghcr.io/ferretdb/ferretdb:0",
+				"ghcr.io/ferretdb/ferretdb:0.1",
+				"ghcr.io/ferretdb/ferretdb:0.1.0",
+				"ghcr.io/ferretdb/ferretdb:latest",
+				"quay.io/ferretdb/ferretdb:0",
+				"quay.io/ferretdb/ferretdb:0.1",
+				"quay.io/ferretdb/ferretdb:0.1.0",
+				"quay.io/ferretdb/ferretdb:latest",
+			},
+		}
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("release/prereleased", func(t *testing.T) {
+		getenv := testutil.GetEnvFunc(t, map[string]string{
+			"GITHUB_EVENT_NAME": "release",
+			"GITHUB_REPOSITORY": "FerretDB/FerretDB",
+		})
+
+		event := readEvent(t, "release", filepath.Join("..", "testdata", "release_prereleased.json"))
+
+		actual, _, err := extract(defaultConfig(), getenv, event)
+		require.NoError(t, err)
+
+		// floating tags are not moved
+		expected := &result{
+			allInOneImages: []string{
+				"ferretdb/all-in-one:0.2.0",
+				"ghcr.io/ferretdb/all-in-one:0.2.0",
+				"quay.io/ferretdb/all-in-one:0.2.0",
+			},
+			developmentImages: []string{
+				"ferretdb/ferretdb-dev:0.2.0",
+				"ghcr.io/ferretdb/ferretdb-dev:0.2.0",
+				"quay.io/ferretdb/ferretdb-dev:0.2.0",
+			},
+			productionImages: []string{
+				"ferretdb/ferretdb:0.2.0",
+				"ghcr.io/ferretdb/ferretdb:0.2.0",
+				"quay.io/ferretdb/ferretdb:0.2.0",
+			},
+		}
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("release/created", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "release.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"action": "created", "release": {"tag_name": "v0.1.0"}}`), 0o666))
+
+		getenv := testutil.GetEnvFunc(t, map[string]string{
+			"GITHUB_EVENT_NAME": "release",
+			"GITHUB_REPOSITORY": "FerretDB/FerretDB",
+		})
+
+		event := readEvent(t, "release", path)
+
+		_, _, err := extract(defaultConfig(), getenv, event)
+		require.EqualError(t, err, `unhandled release action "created"`)
+	})
+
+	t.Run("workflow_dispatch/tag", func(t *testing.T) {
+		getenv := testutil.GetEnvFunc(t, map[string]string{
+			"GITHUB_EVENT_NAME": "workflow_dispatch",
+			"GITHUB_REF_NAME":   "main",
+			"GITHUB_REF_TYPE":   "branch",
+			"GITHUB_REPOSITORY": "FerretDB/FerretDB",
+			"INPUT_TAG":         "v2.1.0-beta",
+		})
+
+		actual, _, err := extract(defaultConfig(), getenv, nil)
+		require.NoError(t, err)
+
+		expected := &result{
+			allInOneImages: []string{
+				"ferretdb/all-in-one:2.1.0-beta",
+				"ghcr.io/ferretdb/all-in-one:2.1.0-beta",
+				"quay.io/ferretdb/all-in-one:2.1.0-beta",
+			},
+			developmentImages: []string{
+				"ferretdb/ferretdb-dev:2.1.0-beta",
+				"ghcr.io/ferretdb/ferretdb-dev:2.1.0-beta",
+				"quay.io/ferretdb/ferretdb-dev:2.1.0-beta",
+			},
+			productionImages: []string{
+				"ferretdb/ferretdb:2.1.0-beta",
+				"ghcr.io/ferretdb/ferretdb:2.1.0-beta",
+				"quay.io/ferretdb/ferretdb:2.1.0-beta",
+			},
+		}
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("workflow_dispatch/branch", func(t *testing.T) {
+		getenv := testutil.GetEnvFunc(t, map[string]string{
+			"GITHUB_EVENT_NAME": "workflow_dispatch",
+			"GITHUB_REF_NAME":   "main",
+			"GITHUB_REF_TYPE":   "branch",
+			"GITHUB_REPOSITORY": "FerretDB/FerretDB",
+			"INPUT_TAG":         "",
+		})
+
+		actual, _, err := extract(defaultConfig(), getenv, nil)
+		require.NoError(t, err)
+
+		expected := &result{
+			allInOneImages: []string{
+				"ferretdb/all-in-one:main",
+				"ghcr.io/ferretdb/all-in-one:main",
+				"quay.io/ferretdb/all-in-one:main",
+			},
+			developmentImages: []string{
+				"ferretdb/ferretdb-dev:main",
+				"ghcr.io/ferretdb/ferretdb-dev:main",
+				"quay.io/ferretdb/ferretdb-dev:main",
+			},
+		}
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("workflow_dispatch/tag/wrong", func(t *testing.T) {
+		getenv := testutil.GetEnvFunc(t, map[string]string{
+			"GITHUB_EVENT_NAME": "workflow_dispatch",
+			"GITHUB_REF_NAME":   "main",
+			"GITHUB_REF_TYPE":   "branch",
+			"GITHUB_REPOSITORY": "FerretDB/FerretDB",
+			"INPUT_TAG":         "2.1.0", // no leading v
+		})
+
+		_, _, err := extract(defaultConfig(), getenv, nil)
+		require.EqualError(t, err, `unexpected git tag "2.1.0"`)
+	})
+
+	t.Run("merge_group", func(t *testing.T) {
+		getenv := testutil.GetEnvFunc(t, map[string]string{
+			"GITHUB_EVENT_NAME": "merge_group",
+			"GITHUB_REPOSITORY": "FerretDB/FerretDB",
+		})
+
+		event := readEvent(t, "merge_group", filepath.Join("..", "testdata", "merge_group.json"))
+
+		actual, _, err := extract(defaultConfig(), getenv, event)
+		require.NoError(t, err)
+
+		expected := &result{
+			allInOneImages: []string{
+				"ferretdb/all-in-one:mq-4b1d1b0b4b3c2a6f3e1e4b8f9a9c6d1e2f3a4b5c",
+				"ghcr.io/ferretdb/all-in-one:mq-4b1d1b0b4b3c2a6f3e1e4b8f9a9c6d1e2f3a4b5c",
+				"quay.io/ferretdb/all-in-one:mq-4b1d1b0b4b3c2a6f3e1e4b8f9a9c6d1e2f3a4b5c",
+			},
+			developmentImages: []string{
+				"ferretdb/ferretdb-dev:mq-4b1d1b0b4b3c2a6f3e1e4b8f9a9c6d1e2f3a4b5c",
+				"ghcr.io/ferretdb/ferretdb-dev:mq-4b1d1b0b4b3c2a6f3e1e4b8f9a9c6d1e2f3a4b5c",
+				"quay.io/ferretdb/ferretdb-dev:mq-4b1d1b0b4b3c2a6f3e1e4b8f9a9c6d1e2f3a4b5c",
+			},
+		}
+		assert.Equal(t, expected, actual)
+	})
 }
 
 func TestExtractOther(t *testing.T) {
@@ -335,7 +569,7 @@ func TestExtractOther(t *testing.T) {
 			"GITHUB_REPOSITORY": "FerretDB/some-repo",
 		})
 
-		actual, err := extract(getenv)
+		actual, _, err := extract(defaultConfig(), getenv, nil)
 		require.NoError(t, err)
 
 		expected := &result{
@@ -346,6 +580,103 @@ func TestExtractOther(t *testing.T) {
 		assert.Equal(t, expected, actual)
 	})
 
+	t.Run("pull_request/sanitize", func(t *testing.T) {
+		getenv := testutil.GetEnvFunc(t, map[string]string{
+			"GITHUB_BASE_REF":   "main",
+			"GITHUB_EVENT_NAME": "pull_request",
+			"GITHUB_HEAD_REF":   "user/Fix+bug#42",
+			"GITHUB_REF_NAME":   "1/merge",
+			"GITHUB_REF_TYPE":   "branch",
+			"GITHUB_REPOSITORY": "FerretDB/some-repo",
+		})
+
+		actual, _, err := extract(defaultConfig(), getenv, nil)
+		require.NoError(t, err)
+
+		expected := &result{
+			developmentImages: []string{
+				"ghcr.io/ferretdb/some-repo-dev:pr-fix-bug-42-7d88781f",
+			},
+		}
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("pull_request/long", func(t *testing.T) {
+		getenv := testutil.GetEnvFunc(t, map[string]string{
+			"GITHUB_BASE_REF":   "main",
+			"GITHUB_EVENT_NAME": "pull_request",
+			"GITHUB_HEAD_REF":   strings.Repeat("long-", 30),
+			"GITHUB_REF_NAME":   "1/merge",
+			"GITHUB_REF_TYPE":   "branch",
+			"GITHUB_REPOSITORY": "FerretDB/some-repo",
+		})
+
+		actual, _, err := extract(defaultConfig(), getenv, nil)
+		require.NoError(t, err)
+
+		require.Len(t, actual.developmentImages, 1)
+		_, tag, _ := strings.Cut(actual.developmentImages[0], ":")
+		assert.Len(t, tag, maxTagLength)
+		assert.True(t, strings.HasPrefix(tag, "pr-long-long-"), "%s", tag)
+	})
+
+	t.Run("pull_request/unicode", func(t *testing.T) {
+		getenv := testutil.GetEnvFunc(t, map[string]string{
+			"GITHUB_BASE_REF":   "main",
+			"GITHUB_EVENT_NAME": "pull_request",
+			"GITHUB_HEAD_REF":   "user/ветка",
+			"GITHUB_REF_NAME":   "1/merge",
+			"GITHUB_REF_TYPE":   "branch",
+			"GITHUB_REPOSITORY": "FerretDB/some-repo",
+		})
+
+		cfg := defaultConfig()
+		cfg.Tags = []tagConfig{{Ref: refPullRequest, Template: "{branch}"}}
+
+		actual, _, err := extract(cfg, getenv, nil)
+		require.NoError(t, err)
+
+		expected := &result{
+			developmentImages: []string{
+				"ghcr.io/ferretdb/some-repo-dev:d3638b68",
+			},
+		}
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("pull_request/empty", func(t *testing.T) {
+		getenv := testutil.GetEnvFunc(t, map[string]string{
+			"GITHUB_BASE_REF":   "main",
+			"GITHUB_EVENT_NAME": "pull_request",
+			"GITHUB_HEAD_REF":   "user/",
+			"GITHUB_REF_NAME":   "1/merge",
+			"GITHUB_REF_TYPE":   "branch",
+			"GITHUB_REPOSITORY": "FerretDB/some-repo",
+		})
+
+		_, _, err := extract(defaultConfig(), getenv, nil)
+		require.Error(t, err)
+		assert.Equal(t, `no valid Docker tag for branch "user/"`, err.Error())
+	})
+
+	t.Run("pull_request/repository", func(t *testing.T) {
+		getenv := testutil.GetEnvFunc(t, map[string]string{
+			"GITHUB_BASE_REF":   "main",
+			"GITHUB_EVENT_NAME": "pull_request",
+			"GITHUB_HEAD_REF":   "extract-docker-tag",
+			"GITHUB_REF_NAME":   "1/merge",
+			"GITHUB_REF_TYPE":   "branch",
+			"GITHUB_REPOSITORY": "FerretDB/-some.repo",
+		})
+
+		_, _, err := extract(defaultConfig(), getenv, nil)
+		require.Error(t, err)
+
+		expected := `invalid image name "ghcr.io/ferretdb/-some.repo-dev" for repository "ferretdb/-some.repo" ` +
+			`and pull_request "extract-docker-tag": invalid path component "-some.repo-dev"`
+		assert.Equal(t, expected, err.Error())
+	})
+
 	t.Run("push/main", func(t *testing.T) {
 		getenv := testutil.GetEnvFunc(t, map[string]string{
 			"GITHUB_BASE_REF":   "",
@@ -356,7 +687,7 @@ func TestExtractOther(t *testing.T) {
 			"GITHUB_REPOSITORY": "FerretDB/some-repo",
 		})
 
-		actual, err := extract(getenv)
+		actual, _, err := extract(defaultConfig(), getenv, nil)
 		require.NoError(t, err)
 
 		expected := &result{
@@ -377,17 +708,19 @@ func TestExtractOther(t *testing.T) {
 			"GITHUB_REPOSITORY": "FerretDB/some-repo",
 		})
 
-		actual, err := extract(getenv)
+		actual, _, err := extract(defaultConfig(), getenv, nil)
 		require.NoError(t, err)
 
 		expected := &result{
 			developmentImages: []string{
 				"ghcr.io/ferretdb/some-repo-dev:2",
+				"ghcr.io/ferretdb/some-repo-dev:2.1",
 				"ghcr.io/ferretdb/some-repo-dev:2.1.0",
 				"ghcr.io/ferretdb/some-repo-dev:latest",
 			},
 			productionImages: []string{
 				"ghcr.io/ferretdb/some-repo:2",
+				"ghcr.io/ferretdb/some-repo:2.1",
 				"ghcr.io/ferretdb/some-repo:2.1.0",
 				"ghcr.io/ferretdb/some-repo:latest",
 			},
@@ -419,6 +752,7 @@ func TestResults(t *testing.T) {
 	getenv := testutil.GetEnvFunc(t, map[string]string{
 		"GITHUB_STEP_SUMMARY": summaryF.Name(),
 		"GITHUB_OUTPUT":       outputF.Name(),
+		"INPUT_JSON-FILE":     "",
 	})
 	action := githubactions.New(githubactions.WithGetenv(getenv), githubactions.WithWriter(&stdout))
 
@@ -434,27 +768,27 @@ func TestResults(t *testing.T) {
 		},
 	}
 
-	setResults(action, result)
+	require.NoError(t, setResults(action, result))
 
 	expectedStdout := strings.ReplaceAll(`
 ::notice::All-in-one: ferretdb/all-in-one:2.1.0 (see https://hub.docker.com/r/ferretdb/all-in-one/tags)
 ::notice::Development: ghcr.io/ferretdb/ferretdb-dev:2 (see https://ghcr.io/ferretdb/ferretdb-dev:2)
 ::notice::Production: quay.io/ferretdb/ferretdb:latest (see https://quay.io/ferretdb/ferretdb:latest)
- |Type        |Image                                                                            |
- |----        |-----                                                                            |
- |All-in-one  |['ferretdb/all-in-one:2.1.0'](https://hub.docker.com/r/ferretdb/all-in-one/tags) |
- |Development |['ghcr.io/ferretdb/ferretdb-dev:2'](https://ghcr.io/ferretdb/ferretdb-dev:2)     |
- |Production  |['quay.io/ferretdb/ferretdb:latest'](https://quay.io/ferretdb/ferretdb:latest)   |
+| Type | Image |
+| --- | --- |
+| All-in-one | ['ferretdb/all-in-one:2.1.0'](https://hub.docker.com/r/ferretdb/all-in-one/tags) |
+| Development | ['ghcr.io/ferretdb/ferretdb-dev:2'](https://ghcr.io/ferretdb/ferretdb-dev:2) |
+| Production | ['quay.io/ferretdb/ferretdb:latest'](https://quay.io/ferretdb/ferretdb:latest) |
 
 `[1:], "'", "`")
 	assert.Equal(t, expectedStdout, stdout.String(), "stdout does not match")
 
 	expectedSummary := strings.ReplaceAll(`
- |Type        |Image                                                                            |
- |----        |-----                                                                            |
- |All-in-one  |['ferretdb/all-in-one:2.1.0'](https://hub.docker.com/r/ferretdb/all-in-one/tags) |
- |Development |['ghcr.io/ferretdb/ferretdb-dev:2'](https://ghcr.io/ferretdb/ferretdb-dev:2)     |
- |Production  |['quay.io/ferretdb/ferretdb:latest'](https://quay.io/ferretdb/ferretdb:latest)   |
+| Type | Image |
+| --- | --- |
+| All-in-one | ['ferretdb/all-in-one:2.1.0'](https://hub.docker.com/r/ferretdb/all-in-one/tags) |
+| Development | ['ghcr.io/ferretdb/ferretdb-dev:2'](https://ghcr.io/ferretdb/ferretdb-dev:2) |
+| Production | ['quay.io/ferretdb/ferretdb:latest'](https://quay.io/ferretdb/ferretdb:latest) |
 
 `[1:], "'", "`")
 	b, err := io.ReadAll(summaryF)
@@ -471,7 +805,12 @@ _GitHubActionsFileCommandDelimeter_
 production_images<<_GitHubActionsFileCommandDelimeter_
 quay.io/ferretdb/ferretdb:latest
 _GitHubActionsFileCommandDelimeter_
-`[1:]
+`[1:] +
+		"json<<_GitHubActionsFileCommandDelimeter_\n" +
+		`{"all_in_one_images":["ferretdb/all-in-one:2.1.0"],` +
+		`"development_images":["ghcr.io/ferretdb/ferretdb-dev:2"],` +
+		`"production_images":["quay.io/ferretdb/ferretdb:latest"]}` + "\n" +
+		"_GitHubActionsFileCommandDelimeter_\n"
 	b, err = io.ReadAll(outputF)
 	require.NoError(t, err)
 	assert.Equal(t, expectedOutput, string(b), "output parameters does not match")
@@ -0,0 +1,121 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package extractdockertag
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// See https://github.com/distribution/reference/blob/main/reference.go for Docker reference grammar.
+
+const (
+	// maxTagLength is the maximal length of Docker tag.
+	maxTagLength = 128
+
+	// maxNameLength is the maximal length of Docker image name, including registry domain.
+	maxNameLength = 255
+
+	// tagHashLength is the length of hash suffix of shortened tags.
+	tagHashLength = 8
+
+	// domainComponent matches a single dot-separated component of registry domain.
+	domainComponent = `[A-Za-z0-9](?:[A-Za-z0-9-]*[A-Za-z0-9])?`
+)
+
+var (
+	// invalidTagCharsRe matches sequences of characters that are not allowed in Docker tags.
+	invalidTagCharsRe = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+	// domainRe matches registry domain with optional port, like `localhost:5000`.
+	domainRe = regexp.MustCompile(`^` + domainComponent + `(?:\.` + domainComponent + `)*(?::[0-9]+)?$`)
+
+	// pathComponentRe matches image name's path components.
+	pathComponentRe = regexp.MustCompile(`^[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*$`)
+)
+
+// sanitizeBranch returns branch name s that could be used in Docker tags, or empty string if s is empty.
+//
+// Sequences of invalid characters are replaced by `-`; leading and trailing `-` and `.` are removed.
+// If that changes the name, a hash of the original name is appended,
+// so branches like `fix+bug` and `fix#bug` or `ветка` and `ёлка` produce different tags.
+func sanitizeBranch(s string) string {
+	if s == "" {
+		return ""
+	}
+
+	res := invalidTagCharsRe.ReplaceAllString(s, "-")
+	res = strings.Trim(res, "-.")
+
+	if res == s {
+		return res
+	}
+
+	h := sha256.Sum256([]byte(s))
+	hash := hex.EncodeToString(h[:])[:tagHashLength]
+
+	if res == "" {
+		return hash
+	}
+
+	return res + "-" + hash
+}
+
+// sanitizeTag returns valid Docker tag for s, or empty string if there is none.
+//
+// Sequences of invalid characters are replaced by `-`; leading and trailing `-` and `.` are removed.
+// Tags that are too long are truncated, and a hash of the original string is appended,
+// so different long strings with the same prefix produce different tags.
+func sanitizeTag(s string) string {
+	res := invalidTagCharsRe.ReplaceAllString(s, "-")
+	res = strings.Trim(res, "-.")
+
+	if len(res) > maxTagLength {
+		h := sha256.Sum256([]byte(s))
+		res = res[:maxTagLength-tagHashLength-1] + "-" + hex.EncodeToString(h[:])[:tagHashLength]
+	}
+
+	return res
+}
+
+// validateImageName checks that image name without tag, like `ghcr.io/ferretdb/ferretdb`,
+// is a valid Docker reference.
+func validateImageName(name string) error {
+	if len(name) > maxNameLength {
+		return fmt.Errorf("name is longer than %d characters", maxNameLength)
+	}
+
+	components := strings.Split(name, "/")
+
+	// the first component is a registry domain only if it looks like one
+	if first := components[0]; len(components) > 1 && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		if !domainRe.MatchString(first) {
+			return fmt.Errorf("invalid domain %q", first)
+		}
+
+		components = components[1:]
+	}
+
+	for _, c := range components {
+		if !pathComponentRe.MatchString(c) {
+			return fmt.Errorf("invalid path component %q", c)
+		}
+	}
+
+	return nil
+}
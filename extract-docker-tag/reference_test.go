@@ -0,0 +1,210 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package extractdockertag
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// tagRe matches valid Docker tags.
+var tagRe = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]{0,127}$`)
+
+func TestSanitizeTag(t *testing.T) {
+	t.Parallel()
+
+	long := strings.Repeat("a", 200)
+
+	cases := []struct {
+		name     string
+		tag      string
+		expected string
+	}{{
+		name:     "Valid",
+		tag:      "pr-extract_docker.tag",
+		expected: "pr-extract_docker.tag",
+	}, {
+		name:     "Invalid",
+		tag:      "pr-fix+bug#42",
+		expected: "pr-fix-bug-42",
+	}, {
+		name:     "Sequence",
+		tag:      "pr-a  +  b",
+		expected: "pr-a-b",
+	}, {
+		name:     "Leading",
+		tag:      ".-_foo-.",
+		expected: "_foo",
+	}, {
+		name:     "Empty",
+		tag:      "#!",
+		expected: "",
+	}, {
+		name:     "Max",
+		tag:      long[:maxTagLength],
+		expected: long[:maxTagLength],
+	}, {
+		name:     "Long",
+		tag:      long,
+		expected: long[:maxTagLength-tagHashLength-1] + "-c2a908d9",
+	}, {
+		name:     "LongOther",
+		tag:      long + "b",
+		expected: long[:maxTagLength-tagHashLength-1] + "-830c8d21",
+	}}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, sanitizeTag(tc.tag))
+		})
+	}
+}
+
+func TestSanitizeBranch(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name     string
+		branch   string
+		expected string
+	}{{
+		name:     "Valid",
+		branch:   "extract_docker.tag",
+		expected: "extract_docker.tag",
+	}, {
+		name:     "Invalid",
+		branch:   "fix+bug",
+		expected: "fix-bug-2c8a8b52",
+	}, {
+		name:     "InvalidOther",
+		branch:   "fix#bug",
+		expected: "fix-bug-251fd5f7",
+	}, {
+		name:     "Unicode",
+		branch:   "ветка",
+		expected: "d3638b68",
+	}, {
+		name:     "UnicodeOther",
+		branch:   "ёлка",
+		expected: "ad862cf2",
+	}, {
+		name:     "Leading",
+		branch:   ".-_foo-.",
+		expected: "_foo-d8af0dd7",
+	}, {
+		name:     "Empty",
+		branch:   "",
+		expected: "",
+	}}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			actual := sanitizeBranch(tc.branch)
+			assert.Equal(t, tc.expected, actual)
+
+			if actual != "" {
+				assert.Regexp(t, tagRe, "pr-"+actual)
+			}
+		})
+	}
+}
+
+func FuzzSanitizeTag(f *testing.F) {
+	for _, tag := range []string{"pr-extract-docker-tag", "pr-fix+bug#42", "pr-ветка", "-", strings.Repeat("long-", 30)} {
+		f.Add(tag)
+	}
+
+	f.Fuzz(func(t *testing.T, tag string) {
+		actual := sanitizeTag(tag)
+
+		if actual == "" {
+			return
+		}
+
+		assert.Regexp(t, tagRe, actual)
+
+		// sanitized tags are not changed again
+		if len(actual) <= maxTagLength {
+			assert.Equal(t, actual, sanitizeTag(actual))
+		}
+
+		// valid tags are not changed, except for trailing characters
+		if tagRe.MatchString(tag) && !strings.HasSuffix(tag, "-") && !strings.HasSuffix(tag, ".") {
+			assert.Equal(t, tag, actual)
+		}
+	})
+}
+
+func TestValidateImageName(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		err  string
+	}{{
+		name: "ghcr.io/ferretdb/all-in-one",
+	}, {
+		name: "ferretdb/ferretdb-dev",
+	}, {
+		name: "localhost:5000/ferretdb/some_repo.name__x",
+	}, {
+		name: "ferretdb",
+	}, {
+		name: "ghcr.io/FerretDB/ferretdb",
+		err:  `invalid path component "FerretDB"`,
+	}, {
+		name: "ghcr.io/ferretdb/-ferretdb",
+		err:  `invalid path component "-ferretdb"`,
+	}, {
+		name: "ghcr.io/ferretdb/ferretdb.",
+		err:  `invalid path component "ferretdb."`,
+	}, {
+		name: "ghcr.io//ferretdb",
+		err:  `invalid path component ""`,
+	}, {
+		name: "-ghcr.io/ferretdb",
+		err:  `invalid domain "-ghcr.io"`,
+	}, {
+		name: "ghcr.io:port/ferretdb",
+		err:  `invalid domain "ghcr.io:port"`,
+	}, {
+		name: "ferretdb/" + strings.Repeat("a", maxNameLength),
+		err:  "name is longer than 255 characters",
+	}}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateImageName(tc.name)
+			if tc.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
@@ -0,0 +1,257 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package extractdockertag
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// https://semver.org/#is-there-a-suggested-regular-expression-regex-to-check-a-semver-string,
+// but with leading `v`.
+var semVerTag = regexp.MustCompile(`^v(?P<major>0|[1-9]\d*)\.(?P<minor>0|[1-9]\d*)\.(?P<patch>0|[1-9]\d*)(?:-(?P<prerelease>(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?(?:\+(?P<buildmetadata>[0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`)
+
+// semVer represents a semantic version; build metadata is ignored.
+type semVer struct {
+	major      uint64
+	minor      uint64
+	patch      uint64
+	prerelease string
+}
+
+// parseSemVer parses git tag like `v1.2.3-beta`.
+func parseSemVer(tag string) (*semVer, error) {
+	match := semVerTag.FindStringSubmatch(tag)
+	if match == nil || len(match) != semVerTag.NumSubexp()+1 {
+		return nil, fmt.Errorf("unexpected git tag %q", tag)
+	}
+
+	var v semVer
+	var err error
+
+	if v.major, err = strconv.ParseUint(match[semVerTag.SubexpIndex("major")], 10, 64); err != nil {
+		return nil, fmt.Errorf("unexpected git tag %q: %w", tag, err)
+	}
+
+	if v.minor, err = strconv.ParseUint(match[semVerTag.SubexpIndex("minor")], 10, 64); err != nil {
+		return nil, fmt.Errorf("unexpected git tag %q: %w", tag, err)
+	}
+
+	if v.patch, err = strconv.ParseUint(match[semVerTag.SubexpIndex("patch")], 10, 64); err != nil {
+		return nil, fmt.Errorf("unexpected git tag %q: %w", tag, err)
+	}
+
+	v.prerelease = match[semVerTag.SubexpIndex("prerelease")]
+
+	return &v, nil
+}
+
+// String returns version without leading `v`, like `1.2.3-beta`.
+func (v *semVer) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.major, v.minor, v.patch)
+	if v.prerelease != "" {
+		s += "-" + v.prerelease
+	}
+
+	return s
+}
+
+// compare returns -1, 0, or 1 if v has lower, equal, or higher precedence than other.
+//
+// See https://semver.org/#spec-item-11.
+func (v *semVer) compare(other *semVer) int {
+	for _, pair := range [][2]uint64{{v.major, other.major}, {v.minor, other.minor}, {v.patch, other.patch}} {
+		if c := compareUint(pair[0], pair[1]); c != 0 {
+			return c
+		}
+	}
+
+	// a version without prerelease part has higher precedence
+	switch {
+	case v.prerelease == other.prerelease:
+		return 0
+	case v.prerelease == "":
+		return 1
+	case other.prerelease == "":
+		return -1
+	}
+
+	ids, otherIDs := strings.Split(v.prerelease, "."), strings.Split(other.prerelease, ".")
+
+	for i := 0; i < len(ids) && i < len(otherIDs); i++ {
+		if c := comparePrereleaseID(ids[i], otherIDs[i]); c != 0 {
+			return c
+		}
+	}
+
+	// a larger set of identifiers has higher precedence
+	return compareUint(uint64(len(ids)), uint64(len(otherIDs)))
+}
+
+// comparePrereleaseID compares a single dot-separated prerelease identifier.
+//
+// Numeric identifiers are compared numerically and have lower precedence than alphanumeric ones,
+// that are compared lexically.
+func comparePrereleaseID(a, b string) int {
+	an, aErr := strconv.ParseUint(a, 10, 64)
+	bn, bErr := strconv.ParseUint(b, 10, 64)
+
+	switch {
+	case aErr == nil && bErr == nil:
+		return compareUint(an, bn)
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+// compareUint returns -1, 0, or 1 if a is less than, equal to, or greater than b.
+func compareUint(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// isHighest returns true if there are no stable versions with higher precedence than v
+// among existing versions within the given scope.
+func isHighest(v *semVer, existing []*semVer, scope string) bool {
+	for _, e := range existing {
+		// floating tags are moved only to stable versions, so prereleases do not matter
+		if e.prerelease != "" {
+			continue
+		}
+
+		switch scope {
+		case floatingMajor:
+			if e.major != v.major {
+				continue
+			}
+		case floatingMinor:
+			if e.major != v.major || e.minor != v.minor {
+				continue
+			}
+		}
+
+		if e.compare(v) > 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// gitTags returns semantic versions of all git tags in the given directory; other tags are ignored.
+func gitTags(dir string) ([]*semVer, error) {
+	b, err := git(dir, "tag", "--list", "v*")
+	if err != nil {
+		return nil, fmt.Errorf("gitTags: %w", err)
+	}
+
+	var res []*semVer
+
+	for _, tag := range strings.Fields(string(b)) {
+		v, err := parseSemVer(strings.ToLower(tag))
+		if err != nil {
+			continue
+		}
+
+		res = append(res, v)
+	}
+
+	return res, nil
+}
+
+// existingVersions returns semantic versions of git tags in the given directory
+// for checking precedence of the given version.
+//
+// Tags are not fetched by `actions/checkout` by default, except for the tag being built,
+// so it returns an error if that version is not among tags,
+// or if the repository is shallow and there are no tags for other versions.
+func existingVersions(dir string, v *semVer) ([]*semVer, error) {
+	existing, err := gitTags(dir)
+	if err != nil {
+		return nil, fmt.Errorf("existingVersions: %w", err)
+	}
+
+	var found, others bool
+
+	for _, e := range existing {
+		if e.compare(v) == 0 {
+			found = true
+		} else {
+			others = true
+		}
+	}
+
+	if !found {
+		return nil, fmt.Errorf(
+			"git tag for version %s is not found in %s; fetch tags (for example, with `fetch-depth: 0`) to check precedence",
+			v, dir,
+		)
+	}
+
+	if others {
+		return existing, nil
+	}
+
+	b, err := git(dir, "rev-parse", "--is-shallow-repository")
+	if err != nil {
+		return nil, fmt.Errorf("existingVersions: %w", err)
+	}
+
+	if strings.TrimSpace(string(b)) == "true" {
+		return nil, fmt.Errorf(
+			"shallow repository in %s has no git tags for versions other than %s; "+
+				"fetch tags (for example, with `fetch-depth: 0`) to check precedence",
+			dir, v,
+		)
+	}
+
+	return existing, nil
+}
+
+// git runs git command with the given arguments in the given directory and returns its output.
+func git(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	b, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("git: %w: %s", err, strings.TrimSpace(stderr.String()))
+		}
+
+		return nil, fmt.Errorf("git: %w", err)
+	}
+
+	return b, nil
+}
@@ -0,0 +1,274 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package extractdockertag
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/github-actions/internal/testutil"
+)
+
+// mustParseSemVer parses git tag or fails the test.
+func mustParseSemVer(t testing.TB, tag string) *semVer {
+	t.Helper()
+
+	v, err := parseSemVer(tag)
+	require.NoError(t, err)
+
+	return v
+}
+
+func TestSemVerCompare(t *testing.T) {
+	t.Parallel()
+
+	// https://semver.org/#spec-item-11, in order of increasing precedence
+	tags := []string{
+		"v1.0.0-alpha",
+		"v1.0.0-alpha.1",
+		"v1.0.0-alpha.beta",
+		"v1.0.0-beta",
+		"v1.0.0-beta.2",
+		"v1.0.0-beta.11",
+		"v1.0.0-rc.1",
+		"v1.0.0",
+		"v1.0.1",
+		"v1.1.0",
+		"v1.10.0",
+		"v2.0.0",
+	}
+
+	for i, a := range tags {
+		for j, b := range tags {
+			expected := compareUint(uint64(i), uint64(j))
+			actual := mustParseSemVer(t, a).compare(mustParseSemVer(t, b))
+			assert.Equal(t, expected, actual, "%s <=> %s", a, b)
+		}
+	}
+
+	assert.Zero(t, mustParseSemVer(t, "v1.0.0+build.1").compare(mustParseSemVer(t, "v1.0.0+build.2")))
+	assert.Equal(t, "1.0.0-beta.11", mustParseSemVer(t, "v1.0.0-beta.11+build").String())
+
+	_, err := parseSemVer("v1.0.99999999999999999999")
+	assert.Error(t, err)
+}
+
+func TestIsHighest(t *testing.T) {
+	t.Parallel()
+
+	var existing []*semVer
+	for _, tag := range []string{"v1.2.0", "v1.2.4", "v1.3.0", "v1.4.0-beta", "v2.0.0"} {
+		existing = append(existing, mustParseSemVer(t, tag))
+	}
+
+	cases := []struct {
+		tag   string
+		minor bool
+		major bool
+		all   bool
+	}{{
+		tag:   "v1.2.5",
+		minor: true,
+	}, {
+		tag:   "v1.2.4",
+		minor: true,
+	}, {
+		tag: "v1.2.3",
+	}, {
+		tag:   "v1.3.1",
+		minor: true,
+		major: true,
+	}, {
+		tag:   "v2.0.1",
+		minor: true,
+		major: true,
+		all:   true,
+	}, {
+		tag:   "v3.0.0",
+		minor: true,
+		major: true,
+		all:   true,
+	}}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.tag, func(t *testing.T) {
+			t.Parallel()
+
+			v := mustParseSemVer(t, tc.tag)
+			assert.Equal(t, tc.minor, isHighest(v, existing, floatingMinor), "minor")
+			assert.Equal(t, tc.major, isHighest(v, existing, floatingMajor), "major")
+			assert.Equal(t, tc.all, isHighest(v, existing, floatingAll), "all")
+		})
+	}
+}
+
+// runGit runs git commands in the given directory or fails the test.
+func runGit(t testing.TB, dir string, commands ...[]string) {
+	t.Helper()
+
+	for _, args := range commands {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		b, err := cmd.CombinedOutput()
+		require.NoError(t, err, "%s", b)
+	}
+}
+
+func TestCheckPrecedence(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	runGit(t, dir, [][]string{
+		{"init", "--quiet"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "--allow-empty", "-m", "Initial"},
+		{"tag", "v2.0.0"},
+		{"tag", "v2.0.1"},
+		{"tag", "v2.1.0"},
+		{"tag", "v2.1.1"},
+		{"tag", "v3.0.0-beta"},
+		{"tag", "not-a-version"},
+	}...)
+
+	existing, err := gitTags(dir)
+	require.NoError(t, err)
+	assert.Len(t, existing, 5)
+
+	cfg := defaultConfig()
+	cfg.CheckPrecedence = true
+
+	getenv := testutil.GetEnvFunc(t, map[string]string{
+		"GITHUB_EVENT_NAME": "push",
+		"GITHUB_REF_NAME":   "v2.0.1",
+		"GITHUB_REF_TYPE":   "tag",
+		"GITHUB_REPOSITORY": "FerretDB/some-repo",
+		"GITHUB_WORKSPACE":  dir,
+	})
+
+	actual, _, err := extract(cfg, getenv, nil)
+	require.NoError(t, err)
+
+	// backport release does not move `latest` and `2`
+	expected := &result{
+		developmentImages: []string{
+			"ghcr.io/ferretdb/some-repo-dev:2.0",
+			"ghcr.io/ferretdb/some-repo-dev:2.0.1",
+		},
+		productionImages: []string{
+			"ghcr.io/ferretdb/some-repo:2.0",
+			"ghcr.io/ferretdb/some-repo:2.0.1",
+		},
+	}
+	assert.Equal(t, expected, actual)
+
+	getenv = testutil.GetEnvFunc(t, map[string]string{
+		"GITHUB_EVENT_NAME": "push",
+		"GITHUB_REF_NAME":   "v2.1.1",
+		"GITHUB_REF_TYPE":   "tag",
+		"GITHUB_REPOSITORY": "FerretDB/some-repo",
+		"GITHUB_WORKSPACE":  dir,
+	})
+
+	actual, _, err = extract(cfg, getenv, nil)
+	require.NoError(t, err)
+
+	// prerelease of the next major version does not matter
+	expected = &result{
+		developmentImages: []string{
+			"ghcr.io/ferretdb/some-repo-dev:2",
+			"ghcr.io/ferretdb/some-repo-dev:2.1",
+			"ghcr.io/ferretdb/some-repo-dev:2.1.1",
+			"ghcr.io/ferretdb/some-repo-dev:latest",
+		},
+		productionImages: []string{
+			"ghcr.io/ferretdb/some-repo:2",
+			"ghcr.io/ferretdb/some-repo:2.1",
+			"ghcr.io/ferretdb/some-repo:2.1.1",
+			"ghcr.io/ferretdb/some-repo:latest",
+		},
+	}
+	assert.Equal(t, expected, actual)
+
+	// tags were not fetched
+	getenv = testutil.GetEnvFunc(t, map[string]string{
+		"GITHUB_EVENT_NAME": "push",
+		"GITHUB_REF_NAME":   "v2.2.0",
+		"GITHUB_REF_TYPE":   "tag",
+		"GITHUB_REPOSITORY": "FerretDB/some-repo",
+		"GITHUB_WORKSPACE":  dir,
+	})
+
+	_, _, err = extract(cfg, getenv, nil)
+	assert.ErrorContains(t, err, "git tag for version 2.2.0 is not found in "+dir)
+
+	_, err = gitTags(t.TempDir())
+	assert.ErrorContains(t, err, "not a git repository")
+}
+
+func TestCheckPrecedenceShallow(t *testing.T) {
+	t.Parallel()
+
+	origin := t.TempDir()
+
+	runGit(t, origin, [][]string{
+		{"init", "--quiet"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "--allow-empty", "-m", "Initial"},
+		{"tag", "v2.0.0"},
+		{"tag", "v2.1.0"},
+		{"tag", "v2.0.1"},
+	}...)
+
+	// that is how `actions/checkout` fetches the tag being built by default
+	dir := t.TempDir()
+
+	runGit(t, dir, [][]string{
+		{"init", "--quiet"},
+		{"fetch", "--quiet", "--no-tags", "--depth=1", "file://" + origin, "+refs/tags/v2.0.1:refs/tags/v2.0.1"},
+	}...)
+
+	cfg := defaultConfig()
+	cfg.CheckPrecedence = true
+
+	getenv := testutil.GetEnvFunc(t, map[string]string{
+		"GITHUB_EVENT_NAME": "push",
+		"GITHUB_REF_NAME":   "v2.0.1",
+		"GITHUB_REF_TYPE":   "tag",
+		"GITHUB_REPOSITORY": "FerretDB/some-repo",
+		"GITHUB_WORKSPACE":  dir,
+	})
+
+	// backport release should not move `latest` and `2`
+	_, _, err := extract(cfg, getenv, nil)
+	assert.ErrorContains(t, err, "shallow repository in "+dir+" has no git tags for versions other than 2.0.1")
+
+	// the first release in a full clone moves them
+	runGit(t, origin, []string{"tag", "--delete", "v2.0.0", "v2.1.0"})
+
+	getenv = testutil.GetEnvFunc(t, map[string]string{
+		"GITHUB_EVENT_NAME": "push",
+		"GITHUB_REF_NAME":   "v2.0.1",
+		"GITHUB_REF_TYPE":   "tag",
+		"GITHUB_REPOSITORY": "FerretDB/some-repo",
+		"GITHUB_WORKSPACE":  origin,
+	})
+
+	actual, _, err := extract(cfg, getenv, nil)
+	require.NoError(t, err)
+	assert.Contains(t, actual.productionImages, "ghcr.io/ferretdb/some-repo:latest")
+}
@@ -12,11 +12,13 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package main
+// Package extractfirsturl implements `extract-first-url` action.
+package extractfirsturl
 
 import (
 	"bufio"
-	"flag"
+	"context"
+	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -24,25 +26,39 @@ import (
 	"github.com/sethvargo/go-githubactions"
 
 	"github.com/FerretDB/github-actions/internal"
+	"github.com/FerretDB/github-actions/internal/output"
 )
 
-func main() {
-	flag.Parse()
-
-	action := githubactions.New()
+// Run runs the action.
+func Run(_ context.Context, action *githubactions.Action) error {
 	internal.DebugEnv(action)
 
 	path := filepath.Join(action.Getenv("GITHUB_WORKSPACE"), "deploy.txt")
-	if u := extractURL(action, path); u != "" {
+
+	u, err := extractURL(path)
+	if err != nil {
+		return err
+	}
+
+	if u != "" {
 		action.Noticef("Extracted URL: %s", u)
 		action.SetOutput("extracted_url", u)
+		output.AddSummary(action, fmt.Sprintf("Extracted URL: <%s>\n", u))
 	}
+
+	return output.SetResult(action, &resultJSON{ExtractedURL: u})
+}
+
+// resultJSON is a result encoded as `json` output.
+type resultJSON struct {
+	ExtractedURL string `json:"extracted_url"`
 }
 
-func extractURL(action *githubactions.Action, path string) string {
+// extractURL returns the first URL in the given file, or empty string if there is none.
+func extractURL(path string) (string, error) {
 	f, err := os.Open(path)
 	if err != nil {
-		action.Fatalf("%s", err)
+		return "", fmt.Errorf("extractURL: %w", err)
 	}
 	defer f.Close()
 
@@ -51,9 +67,13 @@ func extractURL(action *githubactions.Action, path string) string {
 
 	for s.Scan() {
 		if u := re.FindString(s.Text()); u != "" {
-			return u
+			return u, nil
 		}
 	}
 
-	return ""
+	if err = s.Err(); err != nil {
+		return "", fmt.Errorf("extractURL: %w", err)
+	}
+
+	return "", nil
 }
@@ -12,26 +12,32 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package main
+package extractfirsturl
 
 import (
+	"io/fs"
 	"path/filepath"
 	"testing"
 
-	"github.com/sethvargo/go-githubactions"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestExtractURL(t *testing.T) {
-	action := githubactions.New()
-
 	t.Run("Normal", func(t *testing.T) {
-		actual := extractURL(action, filepath.Join("testdata", "deploy.txt"))
+		actual, err := extractURL(filepath.Join("testdata", "deploy.txt"))
+		require.NoError(t, err)
 		assert.Equal(t, "https://1bc44225.ferretdb-docs-dev.pages.dev", actual)
 	})
 
 	t.Run("Empty", func(t *testing.T) {
-		actual := extractURL(action, filepath.Join("testdata", "empty.txt"))
+		actual, err := extractURL(filepath.Join("testdata", "empty.txt"))
+		require.NoError(t, err)
 		assert.Equal(t, "", actual)
 	})
+
+	t.Run("Missing", func(t *testing.T) {
+		_, err := extractURL(filepath.Join("testdata", "missing.txt"))
+		assert.ErrorIs(t, err, fs.ErrNotExist)
+	})
 }
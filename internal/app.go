@@ -0,0 +1,261 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v70/github"
+	"github.com/sethvargo/go-githubactions"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// jwtLifetime is the lifetime of GitHub App JWTs; GitHub allows up to 10 minutes.
+	//
+	// See https://docs.github.com/en/apps/creating-github-apps/authenticating-with-a-github-app/generating-a-json-web-token-jwt-for-a-github-app.
+	//nolint:lll // that URL is long
+	jwtLifetime = 9 * time.Minute
+
+	// tokenRefresh is how long before expiration installation tokens are refreshed.
+	tokenRefresh = 5 * time.Minute
+)
+
+// TokenSource returns a token source for GitHub API clients, selected by action inputs.
+//
+// If `app-id` input is set, GitHub App installation tokens are minted and refreshed
+// with `app-private-key` input (PEM); the installation is taken from `app-installation-id` input,
+// or looked up for `GITHUB_REPOSITORY`.
+// Otherwise, a static token from the given environment variable is used.
+func TokenSource(action *githubactions.Action, tokenVar string) (oauth2.TokenSource, error) {
+	if action.GetInput("app-id") == "" {
+		token := action.Getenv(tokenVar)
+		if token == "" {
+			return nil, fmt.Errorf("%s is not set", tokenVar)
+		}
+
+		return oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token}), nil
+	}
+
+	client, err := appClient(action)
+	if err != nil {
+		return nil, fmt.Errorf("TokenSource: %w", err)
+	}
+
+	s := &appTokenSource{
+		client: client,
+		action: action,
+	}
+
+	if id := action.GetInput("app-installation-id"); id != "" {
+		if s.installationID, err = strconv.ParseInt(id, 10, 64); err != nil {
+			return nil, fmt.Errorf("TokenSource: invalid app-installation-id: %w", err)
+		}
+	} else {
+		var ok bool
+		if s.owner, s.repo, ok = strings.Cut(action.Getenv("GITHUB_REPOSITORY"), "/"); !ok {
+			return nil, fmt.Errorf("TokenSource: app-installation-id or GITHUB_REPOSITORY should be set")
+		}
+	}
+
+	return oauth2.ReuseTokenSourceWithExpiry(nil, s, tokenRefresh), nil
+}
+
+// TokenLogin returns the login of the user or bot that acts with the given client created by GitHubClient.
+//
+// GitHub App installation tokens act as `<app-slug>[bot]`.
+// Automatic GITHUB_TOKEN is provided by GitHub Actions App that can't get the authenticated user,
+// so `github-actions[bot]` is returned for it.
+func TokenLogin(ctx context.Context, action *githubactions.Action, client *github.Client) (string, error) {
+	if action.GetInput("app-id") != "" {
+		jwtClient, err := appClient(action)
+		if err != nil {
+			return "", fmt.Errorf("TokenLogin: %w", err)
+		}
+
+		app, _, err := jwtClient.Apps.Get(ctx, "")
+		if err != nil {
+			return "", fmt.Errorf("TokenLogin: %w", err)
+		}
+
+		return app.GetSlug() + "[bot]", nil
+	}
+
+	user, _, err := client.Users.Get(ctx, "")
+	if err == nil {
+		return user.GetLogin(), nil
+	}
+
+	if IsForbidden(err) {
+		return "github-actions[bot]", nil
+	}
+
+	return "", fmt.Errorf("TokenLogin: %w", err)
+}
+
+// appClient returns GitHub API client authenticated as GitHub App
+// with `app-id` and `app-private-key` inputs.
+func appClient(action *githubactions.Action) (*github.Client, error) {
+	appID, err := strconv.ParseInt(action.GetInput("app-id"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("appClient: invalid app-id: %w", err)
+	}
+
+	key, err := parsePrivateKey(action.GetInput("app-private-key"))
+	if err != nil {
+		return nil, fmt.Errorf("appClient: %w", err)
+	}
+
+	jwt := oauth2.ReuseTokenSource(nil, &jwtTokenSource{appID: appID, key: key, now: time.Now})
+
+	// don't use NewTransport: installation tokens in responses should not be logged
+	client := github.NewClient(&http.Client{
+		Transport: &oauth2.Transport{
+			Base:   NewRateLimitTransport(http.DefaultTransport, action),
+			Source: jwt,
+		},
+	})
+
+	if u := action.Getenv("GITHUB_API_URL"); u != "" {
+		if client.BaseURL, err = url.Parse(strings.TrimSuffix(u, "/") + "/"); err != nil {
+			return nil, fmt.Errorf("appClient: %w", err)
+		}
+	}
+
+	return client, nil
+}
+
+// parsePrivateKey parses GitHub App's RSA private key in PEM format (PKCS #1 or PKCS #8).
+func parsePrivateKey(s string) (*rsa.PrivateKey, error) {
+	if s == "" {
+		return nil, fmt.Errorf("parsePrivateKey: app-private-key is not set")
+	}
+
+	block, _ := pem.Decode([]byte(s))
+	if block == nil {
+		return nil, fmt.Errorf("parsePrivateKey: app-private-key is not in PEM format")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parsePrivateKey: %w", err)
+	}
+
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("parsePrivateKey: expected RSA key, got %T", key)
+	}
+
+	return rsaKey, nil
+}
+
+// jwtTokenSource is an oauth2.TokenSource that generates JWTs for GitHub App authentication.
+type jwtTokenSource struct {
+	appID int64
+	key   *rsa.PrivateKey
+	now   func() time.Time
+}
+
+// Token implements the oauth2.TokenSource interface.
+func (s *jwtTokenSource) Token() (*oauth2.Token, error) {
+	now := s.now()
+
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return nil, fmt.Errorf("jwtTokenSource.Token: %w", err)
+	}
+
+	claims, err := json.Marshal(map[string]any{
+		// allow some clock drift
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(jwtLifetime).Unix(),
+		"iss": strconv.FormatInt(s.appID, 10),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("jwtTokenSource.Token: %w", err)
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+
+	hash := sha256.Sum256([]byte(unsigned))
+
+	sig, err := rsa.SignPKCS1v15(nil, s.key, crypto.SHA256, hash[:])
+	if err != nil {
+		return nil, fmt.Errorf("jwtTokenSource.Token: %w", err)
+	}
+
+	return &oauth2.Token{
+		AccessToken: unsigned + "." + base64.RawURLEncoding.EncodeToString(sig),
+		Expiry:      now.Add(jwtLifetime),
+	}, nil
+}
+
+// appTokenSource is an oauth2.TokenSource that mints GitHub App installation tokens.
+type appTokenSource struct {
+	client *github.Client // authenticated with JWT
+	action *githubactions.Action
+
+	installationID int64
+
+	// used for installation lookup if installationID is not set
+	owner string
+	repo  string
+}
+
+// Token implements the oauth2.TokenSource interface.
+func (s *appTokenSource) Token() (*oauth2.Token, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	if s.installationID == 0 {
+		inst, _, err := s.client.Apps.FindRepositoryInstallation(ctx, s.owner, s.repo)
+		if err != nil {
+			return nil, fmt.Errorf("appTokenSource.Token: %w", err)
+		}
+
+		s.installationID = inst.GetID()
+		s.action.Debugf("Found GitHub App installation %d for %s/%s.", s.installationID, s.owner, s.repo)
+	}
+
+	token, _, err := s.client.Apps.CreateInstallationToken(ctx, s.installationID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("appTokenSource.Token: %w", err)
+	}
+
+	s.action.AddMask(token.GetToken())
+	s.action.Debugf("Minted GitHub App installation token, expires at %s.", token.GetExpiresAt().Format(time.RFC3339))
+
+	return &oauth2.Token{
+		AccessToken: token.GetToken(),
+		Expiry:      token.GetExpiresAt().Time,
+	}, nil
+}
@@ -0,0 +1,334 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v70/github"
+	"github.com/sethvargo/go-githubactions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+// appStub is a stub GitHub API server for GitHub App authentication.
+type appStub struct {
+	t   testing.TB
+	key *rsa.PublicKey
+
+	// how long minted tokens are valid
+	tokenLifetime time.Duration
+
+	m        sync.Mutex
+	lookups  int
+	mints    int
+	requests []string
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (s *appStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+
+	jwt, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !assert.True(s.t, ok) || !s.verify(jwt) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	switch r.Method + " " + r.URL.Path {
+	case "GET /repos/FerretDB/github-actions/installation":
+		s.lookups++
+		fmt.Fprint(w, `{"id": 42}`)
+
+	case "GET /app":
+		fmt.Fprint(w, `{"id": 123, "slug": "ferretdb-bot"}`)
+
+	case "POST /app/installations/42/access_tokens":
+		s.mints++
+		expires := time.Now().Add(s.tokenLifetime).UTC().Format(time.RFC3339)
+		fmt.Fprintf(w, `{"token": "ghs_token%d", "expires_at": %q}`, s.mints, expires)
+
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// verify checks JWT signature and claims.
+func (s *appStub) verify(jwt string) bool {
+	parts := strings.Split(jwt, ".")
+	if !assert.Len(s.t, parts, 3) {
+		return false
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	require.NoError(s.t, err)
+
+	hash := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if !assert.NoError(s.t, rsa.VerifyPKCS1v15(s.key, crypto.SHA256, hash[:], sig)) {
+		return false
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(parts[1])
+	require.NoError(s.t, err)
+
+	var claims struct {
+		Iat int64  `json:"iat"`
+		Exp int64  `json:"exp"`
+		Iss string `json:"iss"`
+	}
+	require.NoError(s.t, json.Unmarshal(b, &claims))
+
+	now := time.Now().Unix()
+
+	return assert.Equal(s.t, "123", claims.Iss) &&
+		assert.Less(s.t, claims.Iat, now) &&
+		assert.Greater(s.t, claims.Exp, now) &&
+		assert.LessOrEqual(s.t, claims.Exp-claims.Iat, int64(10*60))
+}
+
+func TestTokenSource(t *testing.T) {
+	t.Parallel()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	pkcs1 := string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+
+	b, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	pkcs8 := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: b}))
+
+	cases := []struct {
+		name             string
+		env              map[string]string
+		tokenLifetime    time.Duration
+		expectedTokens   []string // for two Token calls
+		expectedRequests []string
+		expectedErr      string
+	}{{
+		name:           "Static",
+		env:            map[string]string{"GITHUB_TOKEN": "ghp_static"},
+		expectedTokens: []string{"ghp_static", "ghp_static"},
+	}, {
+		name:        "StaticNotSet",
+		env:         map[string]string{},
+		expectedErr: "GITHUB_TOKEN is not set",
+	}, {
+		name: "AppLookup",
+		env: map[string]string{
+			"INPUT_APP-ID":          "123",
+			"INPUT_APP-PRIVATE-KEY": pkcs1,
+			"GITHUB_REPOSITORY":     "FerretDB/github-actions",
+		},
+		tokenLifetime:  time.Hour,
+		expectedTokens: []string{"ghs_token1", "ghs_token1"},
+		expectedRequests: []string{
+			"GET /repos/FerretDB/github-actions/installation",
+			"POST /app/installations/42/access_tokens",
+		},
+	}, {
+		name: "AppInstallationID",
+		env: map[string]string{
+			"INPUT_APP-ID":              "123",
+			"INPUT_APP-PRIVATE-KEY":     pkcs8,
+			"INPUT_APP-INSTALLATION-ID": "42",
+		},
+		tokenLifetime:  time.Hour,
+		expectedTokens: []string{"ghs_token1", "ghs_token1"},
+		expectedRequests: []string{
+			"POST /app/installations/42/access_tokens",
+		},
+	}, {
+		name: "AppRefresh",
+		env: map[string]string{
+			"INPUT_APP-ID":              "123",
+			"INPUT_APP-PRIVATE-KEY":     pkcs1,
+			"INPUT_APP-INSTALLATION-ID": "42",
+		},
+		tokenLifetime:  tokenRefresh / 2,
+		expectedTokens: []string{"ghs_token1", "ghs_token2"},
+		expectedRequests: []string{
+			"POST /app/installations/42/access_tokens",
+			"POST /app/installations/42/access_tokens",
+		},
+	}, {
+		name: "AppInvalidKey",
+		env: map[string]string{
+			"INPUT_APP-ID":          "123",
+			"INPUT_APP-PRIVATE-KEY": "invalid",
+		},
+		expectedErr: "TokenSource: appClient: parsePrivateKey: app-private-key is not in PEM format",
+	}, {
+		name: "AppNoInstallation",
+		env: map[string]string{
+			"INPUT_APP-ID":          "123",
+			"INPUT_APP-PRIVATE-KEY": pkcs1,
+		},
+		expectedErr: "TokenSource: app-installation-id or GITHUB_REPOSITORY should be set",
+	}}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			stub := &appStub{t: t, key: &key.PublicKey, tokenLifetime: tc.tokenLifetime}
+			srv := httptest.NewServer(stub)
+			t.Cleanup(srv.Close)
+
+			env := map[string]string{"GITHUB_API_URL": srv.URL}
+			for k, v := range tc.env {
+				env[k] = v
+			}
+
+			action := githubactions.New(
+				githubactions.WithWriter(io.Discard),
+				githubactions.WithGetenv(func(key string) string { return env[key] }),
+			)
+
+			ts, err := TokenSource(action, "GITHUB_TOKEN")
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+
+			for _, expected := range tc.expectedTokens {
+				token, err := ts.Token()
+				require.NoError(t, err)
+				assert.Equal(t, expected, token.AccessToken)
+			}
+
+			assert.Equal(t, tc.expectedRequests, stub.requests)
+		})
+	}
+}
+
+func TestTokenLogin(t *testing.T) {
+	t.Parallel()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	pkcs1 := string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+
+	cases := []struct {
+		name     string
+		env      map[string]string
+		status   int // for GET /user
+		expected string
+		err      string
+	}{{
+		name:     "User",
+		env:      map[string]string{"GITHUB_TOKEN": "ghp_static"},
+		status:   http.StatusOK,
+		expected: "octocat",
+	}, {
+		name:     "Actions",
+		env:      map[string]string{"GITHUB_TOKEN": "ghs_actions"},
+		status:   http.StatusForbidden,
+		expected: "github-actions[bot]",
+	}, {
+		name:   "Unauthorized",
+		env:    map[string]string{"GITHUB_TOKEN": "ghp_invalid"},
+		status: http.StatusUnauthorized,
+		err:    "401 Bad credentials",
+	}, {
+		name: "App",
+		env: map[string]string{
+			"INPUT_APP-ID":              "123",
+			"INPUT_APP-PRIVATE-KEY":     pkcs1,
+			"INPUT_APP-INSTALLATION-ID": "42",
+		},
+		expected: "ferretdb-bot[bot]",
+	}}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			stub := &appStub{t: t, key: &key.PublicKey, tokenLifetime: time.Hour}
+
+			mux := http.NewServeMux()
+			mux.Handle("/", stub)
+			mux.HandleFunc("GET /user", func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tc.status)
+
+				switch tc.status {
+				case http.StatusOK:
+					fmt.Fprint(w, `{"login": "octocat"}`)
+				case http.StatusForbidden:
+					fmt.Fprint(w, `{"message": "Resource not accessible by integration"}`)
+				default:
+					fmt.Fprint(w, `{"message": "Bad credentials"}`)
+				}
+			})
+
+			srv := httptest.NewServer(mux)
+			t.Cleanup(srv.Close)
+
+			env := map[string]string{"GITHUB_API_URL": srv.URL}
+			for k, v := range tc.env {
+				env[k] = v
+			}
+
+			action := githubactions.New(
+				githubactions.WithWriter(io.Discard),
+				githubactions.WithGetenv(func(key string) string { return env[key] }),
+			)
+
+			ts, err := TokenSource(action, "GITHUB_TOKEN")
+			require.NoError(t, err)
+
+			client := github.NewClient(oauth2.NewClient(context.Background(), ts))
+			client.BaseURL, err = url.Parse(srv.URL + "/")
+			require.NoError(t, err)
+
+			actual, err := TokenLogin(context.Background(), action, client)
+			if tc.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
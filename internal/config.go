@@ -0,0 +1,86 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/sethvargo/go-githubactions"
+	"gopkg.in/yaml.v3"
+)
+
+// DecodeConfig decodes configuration file content into v.
+//
+// Both YAML and JSON are accepted. Unknown fields are rejected.
+// Fields of v that are missing from the file are not changed.
+func DecodeConfig(b []byte, v any) error {
+	d := yaml.NewDecoder(bytes.NewReader(b))
+	d.KnownFields(true)
+
+	if err := d.Decode(v); err != nil {
+		if errors.Is(err, io.EOF) {
+			return errors.New("configuration is empty")
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// LoadConfig loads configuration from the file set by `config` input (relative to GITHUB_WORKSPACE),
+// or from the file with the given default path if input is not set. The content is parsed with parse.
+//
+// If input is not set and the default file does not exist, built-in configuration is returned.
+func LoadConfig[T any](
+	action *githubactions.Action, defaultPath string, parse func([]byte) (T, error), builtin func() T,
+) (T, error) {
+	var zero T
+
+	path := action.GetInput("config")
+	explicit := path != ""
+
+	if !explicit {
+		path = defaultPath
+	}
+
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(action.Getenv("GITHUB_WORKSPACE"), path)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if !explicit && errors.Is(err, os.ErrNotExist) {
+			action.Infof("Configuration file %s does not exist, using built-in configuration.", path)
+			return builtin(), nil
+		}
+
+		return zero, err
+	}
+
+	c, err := parse(b)
+	if err != nil {
+		return zero, fmt.Errorf("invalid configuration file %s: %w", path, err)
+	}
+
+	action.Infof("Using configuration file %s.", path)
+
+	return c, nil
+}
@@ -0,0 +1,160 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sethvargo/go-githubactions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testConfig is a configuration for tests.
+type testConfig struct {
+	Version int    `yaml:"version"`
+	Name    string `yaml:"name"`
+}
+
+// parseTestConfig parses testConfig over built-in one.
+func parseTestConfig(b []byte) (*testConfig, error) {
+	c := builtinTestConfig()
+	if err := DecodeConfig(b, c); err != nil {
+		return nil, err
+	}
+
+	if c.Version != 1 {
+		return nil, errors.New("unsupported version")
+	}
+
+	return c, nil
+}
+
+// builtinTestConfig returns built-in testConfig.
+func builtinTestConfig() *testConfig {
+	return &testConfig{Version: 1, Name: "built-in"}
+}
+
+func TestDecodeConfig(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name     string
+		file     string
+		expected *testConfig
+		err      string
+	}{{
+		name:     "YAML",
+		file:     "version: 1\nname: custom\n",
+		expected: &testConfig{Version: 1, Name: "custom"},
+	}, {
+		name:     "JSON",
+		file:     `{"version": 1, "name": "custom"}`,
+		expected: &testConfig{Version: 1, Name: "custom"},
+	}, {
+		name:     "Missing",
+		file:     "version: 1\n",
+		expected: &testConfig{Version: 1, Name: "built-in"},
+	}, {
+		name: "Empty",
+		file: "",
+		err:  "configuration is empty",
+	}, {
+		name: "UnknownField",
+		file: "version: 1\nnames: custom\n",
+		err:  "yaml: unmarshal errors:\n  line 2: field names not found in type internal.testConfig",
+	}}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			actual := builtinTestConfig()
+
+			err := DecodeConfig([]byte(tc.file), actual)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "custom.yml"), []byte("version: 1\nname: custom\n"), 0o666))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "invalid.yml"), []byte("version: 2\n"), 0o666))
+
+	cases := []struct {
+		name     string
+		input    string
+		expected *testConfig
+		err      string
+	}{{
+		name:     "Missing",
+		expected: builtinTestConfig(),
+	}, {
+		name:  "MissingExplicit",
+		input: "missing.yml",
+		err:   "open " + filepath.Join(dir, "missing.yml") + ": no such file or directory",
+	}, {
+		name:     "Custom",
+		input:    "custom.yml",
+		expected: &testConfig{Version: 1, Name: "custom"},
+	}, {
+		name:     "Absolute",
+		input:    filepath.Join(dir, "custom.yml"),
+		expected: &testConfig{Version: 1, Name: "custom"},
+	}, {
+		name:  "Invalid",
+		input: "invalid.yml",
+		err:   "invalid configuration file " + filepath.Join(dir, "invalid.yml") + ": unsupported version",
+	}}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			env := map[string]string{
+				"GITHUB_WORKSPACE": dir,
+				"INPUT_CONFIG":     tc.input,
+			}
+			action := githubactions.New(
+				githubactions.WithWriter(io.Discard),
+				githubactions.WithGetenv(func(key string) string { return env[key] }),
+			)
+
+			actual, err := LoadConfig(action, "default.yml", parseTestConfig, builtinTestConfig)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
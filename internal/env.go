@@ -16,19 +16,36 @@ package internal
 
 import (
 	"os"
+	"regexp"
 	"sort"
 	"strings"
 
 	"github.com/sethvargo/go-githubactions"
+	"golang.org/x/exp/slices"
 )
 
+// secretInputRe matches environment variables of action inputs with secrets, like `INPUT_APP-PRIVATE-KEY`.
+var secretInputRe = regexp.MustCompile(`^INPUT_.*(TOKEN|SECRET|PASSWORD|PRIVATE-KEY)`)
+
+// RedactEnv returns the value of environment variable with the given name suitable for logging.
+//
+// Values of known secret variables and inputs are replaced entirely; GitHub tokens are redacted in other values.
+func RedactEnv(name, value string) string {
+	if value != "" && (slices.Contains(secretVars, name) || secretInputRe.MatchString(name)) {
+		return redacted
+	}
+
+	return tokenRegexp.ReplaceAllString(value, redacted)
+}
+
 // DebugEnv logs all environment variables that start with `GITHUB_` or `INPUT_`
-// in debug level.
+// in debug level, with secrets redacted.
 func DebugEnv(action *githubactions.Action) {
 	res := make([]string, 0, 30)
 	for _, l := range os.Environ() {
 		if strings.HasPrefix(l, "GITHUB_") || strings.HasPrefix(l, "INPUT_") {
-			res = append(res, l)
+			k, v, _ := strings.Cut(l, "=")
+			res = append(res, k+"="+RedactEnv(k, v))
 		}
 	}
 
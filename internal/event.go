@@ -0,0 +1,297 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v70/github"
+	"github.com/sethvargo/go-githubactions"
+)
+
+// mergeGroupRefRegexp extracts PR number from merge group ref
+// like "refs/heads/gh-readonly-queue/main/pr-123-f0e6a1b2c3d4".
+var mergeGroupRefRegexp = regexp.MustCompile(`/pr-(\d+)-[0-9a-f]+$`)
+
+// ScheduleEvent represents `schedule` event payload.
+//
+// Unlike other events, go-github does not provide a type for it.
+//
+// See https://docs.github.com/en/actions/writing-workflows/choosing-when-your-workflow-runs/events-that-trigger-workflows#schedule.
+//
+//nolint:lll // that URL is long
+type ScheduleEvent struct {
+	Schedule string               `json:"schedule"` // cron expression like "42 3 * * *"
+	Workflow string               `json:"workflow"` // like ".github/workflows/conform-pr.yml"
+	Repo     *github.Repository   `json:"repository,omitempty"`
+	Org      *github.Organization `json:"organization,omitempty"`
+	Sender   *github.User         `json:"sender,omitempty"`
+}
+
+// Event is a GitHub Actions event with its payload and workflow context.
+type Event struct {
+	// Name is the event name like "pull_request" (from GITHUB_EVENT_NAME).
+	Name string
+
+	// Payload is one of:
+	//   - *github.PullRequestEvent for `pull_request` and `pull_request_target`;
+	//   - *github.PullRequestReviewEvent for `pull_request_review`;
+	//   - *github.PullRequestReviewCommentEvent for `pull_request_review_comment`;
+	//   - *github.PushEvent for `push`;
+	//   - *ScheduleEvent for `schedule`;
+	//   - *github.WorkflowRunEvent for `workflow_run`;
+	//   - *github.WorkflowDispatchEvent for `workflow_dispatch`;
+	//   - *github.MergeGroupEvent for `merge_group`;
+	//   - *github.IssueCommentEvent for `issue_comment`;
+	//   - *github.ReleaseEvent for `release`;
+	//   - *github.CreateEvent for `create`.
+	Payload any
+
+	// payload's repository with all fields, or nil
+	repo *github.Repository
+
+	// used when payload does not contain that information
+	repository string // GITHUB_REPOSITORY, like "FerretDB/FerretDB"
+	sha        string // GITHUB_SHA
+	actor      string // GITHUB_ACTOR
+}
+
+// NewEvent returns a new event with the given name and payload,
+// and the workflow context from action's environment variables.
+func NewEvent(action *githubactions.Action, name string, payload any) *Event {
+	return &Event{
+		Name:       name,
+		Payload:    payload,
+		repository: action.Getenv("GITHUB_REPOSITORY"),
+		sha:        action.Getenv("GITHUB_SHA"),
+		actor:      action.Getenv("GITHUB_ACTOR"),
+	}
+}
+
+// ReadEvent reads event from GITHUB_EVENT_PATH path.
+func ReadEvent(action *githubactions.Action) (*Event, error) {
+	eventPath := action.Getenv("GITHUB_EVENT_PATH")
+	if eventPath == "" {
+		return nil, fmt.Errorf("GITHUB_EVENT_PATH is not set")
+	}
+
+	b, err := os.ReadFile(eventPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Debug level requires `ACTIONS_RUNNER_DEBUG` secret to be set to `true`:
+	// https://docs.github.com/en/actions/monitoring-and-troubleshooting-workflows/enabling-debug-logging
+	// Note that `pull_request` events from forks do not have access to secrets,
+	// so that line will not be logged in that case.
+	action.Debugf("Read event from %s:\n%s", eventPath, string(b))
+
+	eventName := action.Getenv("GITHUB_EVENT_NAME")
+	if eventName == "" {
+		return nil, fmt.Errorf("GITHUB_EVENT_NAME is not set")
+	}
+
+	var payload any
+	switch eventName {
+	case "pull_request", "pull_request_target":
+		payload = new(github.PullRequestEvent)
+	case "pull_request_review":
+		payload = new(github.PullRequestReviewEvent)
+	case "pull_request_review_comment":
+		payload = new(github.PullRequestReviewCommentEvent)
+	case "push":
+		payload = new(github.PushEvent)
+	case "schedule":
+		payload = new(ScheduleEvent)
+	case "workflow_run":
+		payload = new(github.WorkflowRunEvent)
+	case "workflow_dispatch":
+		payload = new(github.WorkflowDispatchEvent)
+	case "merge_group":
+		payload = new(github.MergeGroupEvent)
+	case "issue_comment":
+		payload = new(github.IssueCommentEvent)
+	case "release":
+		payload = new(github.ReleaseEvent)
+	case "create":
+		payload = new(github.CreateEvent)
+	default:
+		return nil, fmt.Errorf("unhandled event to unmarshal: %q", eventName)
+	}
+
+	if err := json.Unmarshal(b, payload); err != nil {
+		return nil, err
+	}
+
+	// go-github's type for push event's repository lacks some fields, like license
+	var repo struct {
+		Repository *github.Repository `json:"repository"`
+	}
+	if err := json.Unmarshal(b, &repo); err != nil {
+		return nil, err
+	}
+
+	e := NewEvent(action, eventName, payload)
+	e.repo = repo.Repository
+
+	return e, nil
+}
+
+// Repository returns the payload's repository with all fields, like description and license,
+// or nil if the event was not read from the payload or the payload has no repository.
+func (e *Event) Repository() *github.Repository {
+	return e.repo
+}
+
+// Repo returns owner and name of the repository where the workflow runs.
+func (e *Event) Repo() (owner, repo string) {
+	var r *github.Repository
+
+	switch p := e.Payload.(type) {
+	case *github.PullRequestEvent:
+		r = p.GetRepo()
+	case *github.PullRequestReviewEvent:
+		r = p.GetRepo()
+	case *github.PullRequestReviewCommentEvent:
+		r = p.GetRepo()
+	case *ScheduleEvent:
+		r = p.Repo
+	case *github.WorkflowRunEvent:
+		r = p.GetRepo()
+	case *github.WorkflowDispatchEvent:
+		r = p.GetRepo()
+	case *github.MergeGroupEvent:
+		r = p.GetRepo()
+	case *github.IssueCommentEvent:
+		r = p.GetRepo()
+	case *github.ReleaseEvent:
+		r = p.GetRepo()
+	case *github.CreateEvent:
+		r = p.GetRepo()
+	case *github.PushEvent:
+		// push event has a different repository type
+		if pr := p.GetRepo(); pr != nil {
+			return pr.GetOwner().GetLogin(), pr.GetName()
+		}
+	}
+
+	if r != nil && r.GetName() != "" {
+		return r.GetOwner().GetLogin(), r.GetName()
+	}
+
+	owner, repo, _ = strings.Cut(e.repository, "/")
+	return
+}
+
+// HeadSHA returns the commit SHA the event is about:
+// PR's head, pushed commit, workflow run's head, or merge group's head.
+//
+// For `issue_comment`, it returns an empty string, because the commented PR's head is not in the payload.
+// For other events, it returns GITHUB_SHA.
+func (e *Event) HeadSHA() string {
+	switch p := e.Payload.(type) {
+	case *github.PullRequestEvent:
+		return p.GetPullRequest().GetHead().GetSHA()
+	case *github.PullRequestReviewEvent:
+		return p.GetPullRequest().GetHead().GetSHA()
+	case *github.PullRequestReviewCommentEvent:
+		return p.GetPullRequest().GetHead().GetSHA()
+	case *github.PushEvent:
+		if sha := p.GetAfter(); sha != "" {
+			return sha
+		}
+	case *github.WorkflowRunEvent:
+		return p.GetWorkflowRun().GetHeadSHA()
+	case *github.MergeGroupEvent:
+		return p.GetMergeGroup().GetHeadSHA()
+	case *github.IssueCommentEvent:
+		return ""
+	}
+
+	return e.sha
+}
+
+// PRNumber returns the number of PR the event is about, or 0.
+//
+// For `merge_group`, it is extracted from the merge group ref.
+// For `workflow_run`, the first associated PR is used.
+// For `issue_comment`, it is returned only for comments on PRs.
+func (e *Event) PRNumber() int {
+	switch p := e.Payload.(type) {
+	case *github.PullRequestEvent:
+		return p.GetPullRequest().GetNumber()
+	case *github.PullRequestReviewEvent:
+		return p.GetPullRequest().GetNumber()
+	case *github.PullRequestReviewCommentEvent:
+		return p.GetPullRequest().GetNumber()
+	case *github.WorkflowRunEvent:
+		if prs := p.GetWorkflowRun().PullRequests; len(prs) > 0 {
+			return prs[0].GetNumber()
+		}
+	case *github.MergeGroupEvent:
+		if m := mergeGroupRefRegexp.FindStringSubmatch(p.GetMergeGroup().GetHeadRef()); m != nil {
+			n, _ := strconv.Atoi(m[1])
+			return n
+		}
+	case *github.IssueCommentEvent:
+		if p.GetIssue().IsPullRequest() {
+			return p.GetIssue().GetNumber()
+		}
+	}
+
+	return 0
+}
+
+// Actor returns the login of the user that triggered the workflow (GITHUB_ACTOR),
+// or the event's sender.
+func (e *Event) Actor() string {
+	if e.actor != "" {
+		return e.actor
+	}
+
+	var sender *github.User
+
+	switch p := e.Payload.(type) {
+	case *github.PullRequestEvent:
+		sender = p.GetSender()
+	case *github.PullRequestReviewEvent:
+		sender = p.GetSender()
+	case *github.PullRequestReviewCommentEvent:
+		sender = p.GetSender()
+	case *github.PushEvent:
+		sender = p.GetSender()
+	case *ScheduleEvent:
+		sender = p.Sender
+	case *github.WorkflowRunEvent:
+		sender = p.GetSender()
+	case *github.WorkflowDispatchEvent:
+		sender = p.GetSender()
+	case *github.MergeGroupEvent:
+		sender = p.GetSender()
+	case *github.IssueCommentEvent:
+		sender = p.GetSender()
+	case *github.ReleaseEvent:
+		sender = p.GetSender()
+	case *github.CreateEvent:
+		sender = p.GetSender()
+	}
+
+	return sender.GetLogin()
+}
@@ -0,0 +1,234 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-github/v70/github"
+	"github.com/sethvargo/go-githubactions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadEvent(t *testing.T) {
+	t.Parallel()
+
+	const (
+		prSHA  = "76bd109bca7ca50ccc1839846b788d3f709ae84e"
+		envSHA = "0123456789abcdef0123456789abcdef01234567"
+	)
+
+	cases := []struct {
+		name             string
+		eventName        string
+		file             string
+		actor            string // GITHUB_ACTOR
+		expectedPayload  any    // only type is checked
+		expectedOwner    string
+		expectedRepo     string
+		expectedHeadSHA  string
+		expectedPRNumber int
+		expectedActor    string
+	}{{
+		name:             "PullRequest",
+		eventName:        "pull_request",
+		file:             "pull_request_self.json",
+		expectedPayload:  new(github.PullRequestEvent),
+		expectedOwner:    "AlekSi",
+		expectedRepo:     "FerretDB",
+		expectedHeadSHA:  prSHA,
+		expectedPRNumber: 10,
+		expectedActor:    "AlekSi",
+	}, {
+		name:             "PullRequestTarget",
+		eventName:        "pull_request_target",
+		file:             "pull_request_target_fork.json",
+		actor:            "ferretdb-bot",
+		expectedPayload:  new(github.PullRequestEvent),
+		expectedOwner:    "FerretDB",
+		expectedRepo:     "FerretDB",
+		expectedHeadSHA:  prSHA,
+		expectedPRNumber: 305,
+		expectedActor:    "ferretdb-bot",
+	}, {
+		name:             "PullRequestReview",
+		eventName:        "pull_request_review",
+		file:             "pull_request_review.json",
+		expectedPayload:  new(github.PullRequestReviewEvent),
+		expectedOwner:    "AlekSi",
+		expectedRepo:     "FerretDB",
+		expectedHeadSHA:  prSHA,
+		expectedPRNumber: 10,
+		expectedActor:    "AlekSi",
+	}, {
+		name:             "PullRequestReviewComment",
+		eventName:        "pull_request_review_comment",
+		file:             "pull_request_review_comment.json",
+		expectedPayload:  new(github.PullRequestReviewCommentEvent),
+		expectedOwner:    "AlekSi",
+		expectedRepo:     "FerretDB",
+		expectedHeadSHA:  prSHA,
+		expectedPRNumber: 10,
+		expectedActor:    "AlekSi",
+	}, {
+		name:            "Push",
+		eventName:       "push",
+		file:            "push.json",
+		expectedPayload: new(github.PushEvent),
+		expectedOwner:   "AlekSi",
+		expectedRepo:    "FerretDB",
+		expectedHeadSHA: "07c35a146792157d593e8a4be7ac29ae750dd151",
+		expectedActor:   "AlekSi",
+	}, {
+		name:            "Schedule",
+		eventName:       "schedule",
+		file:            "schedule.json",
+		actor:           "AlekSi",
+		expectedPayload: new(ScheduleEvent),
+		expectedOwner:   "AlekSi",
+		expectedRepo:    "FerretDB",
+		expectedHeadSHA: envSHA,
+		expectedActor:   "AlekSi",
+	}, {
+		name:             "WorkflowRun",
+		eventName:        "workflow_run",
+		file:             "workflow_run.json",
+		expectedPayload:  new(github.WorkflowRunEvent),
+		expectedOwner:    "AlekSi",
+		expectedRepo:     "FerretDB",
+		expectedHeadSHA:  prSHA,
+		expectedPRNumber: 10,
+		expectedActor:    "AlekSi",
+	}, {
+		name:            "WorkflowDispatch",
+		eventName:       "workflow_dispatch",
+		file:            "workflow_dispatch.json",
+		expectedPayload: new(github.WorkflowDispatchEvent),
+		expectedOwner:   "AlekSi",
+		expectedRepo:    "FerretDB",
+		expectedHeadSHA: envSHA,
+		expectedActor:   "AlekSi",
+	}, {
+		name:             "MergeGroup",
+		eventName:        "merge_group",
+		file:             "merge_group.json",
+		expectedPayload:  new(github.MergeGroupEvent),
+		expectedOwner:    "AlekSi",
+		expectedRepo:     "FerretDB",
+		expectedHeadSHA:  "4b1d1b0b4b3c2a6f3e1e4b8f9a9c6d1e2f3a4b5c",
+		expectedPRNumber: 10,
+		expectedActor:    "AlekSi",
+	}, {
+		name:             "IssueComment",
+		eventName:        "issue_comment",
+		file:             "issue_comment.json",
+		expectedPayload:  new(github.IssueCommentEvent),
+		expectedOwner:    "AlekSi",
+		expectedRepo:     "FerretDB",
+		expectedPRNumber: 10,
+		expectedActor:    "AlekSi",
+	}, {
+		name:            "Release",
+		eventName:       "release",
+		file:            "release.json",
+		expectedPayload: new(github.ReleaseEvent),
+		expectedOwner:   "AlekSi",
+		expectedRepo:    "FerretDB",
+		expectedHeadSHA: envSHA,
+		expectedActor:   "AlekSi",
+	}, {
+		name:            "Create",
+		eventName:       "create",
+		file:            "create.json",
+		expectedPayload: new(github.CreateEvent),
+		expectedOwner:   "AlekSi",
+		expectedRepo:    "FerretDB",
+		expectedHeadSHA: envSHA,
+		expectedActor:   "AlekSi",
+	}}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			env := map[string]string{
+				"GITHUB_EVENT_NAME": tc.eventName,
+				"GITHUB_EVENT_PATH": filepath.Join("..", "testdata", tc.file),
+				"GITHUB_REPOSITORY": "FerretDB/github-actions",
+				"GITHUB_SHA":        envSHA,
+				"GITHUB_ACTOR":      tc.actor,
+			}
+			action := githubactions.New(
+				githubactions.WithWriter(io.Discard),
+				githubactions.WithGetenv(func(key string) string { return env[key] }),
+			)
+
+			event, err := ReadEvent(action)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.eventName, event.Name)
+			assert.IsType(t, tc.expectedPayload, event.Payload)
+
+			owner, repo := event.Repo()
+			assert.Equal(t, tc.expectedOwner, owner)
+			assert.Equal(t, tc.expectedRepo, repo)
+			assert.Equal(t, tc.expectedRepo, event.Repository().GetName())
+
+			assert.Equal(t, tc.expectedHeadSHA, event.HeadSHA())
+			assert.Equal(t, tc.expectedPRNumber, event.PRNumber())
+			assert.Equal(t, tc.expectedActor, event.Actor())
+		})
+	}
+}
+
+func TestEventFallback(t *testing.T) {
+	t.Parallel()
+
+	env := map[string]string{
+		"GITHUB_REPOSITORY": "FerretDB/github-actions",
+		"GITHUB_SHA":        "0123456789abcdef0123456789abcdef01234567",
+	}
+	action := githubactions.New(githubactions.WithGetenv(func(key string) string { return env[key] }))
+
+	event := NewEvent(action, "schedule", new(ScheduleEvent))
+
+	owner, repo := event.Repo()
+	assert.Equal(t, "FerretDB", owner)
+	assert.Equal(t, "github-actions", repo)
+	assert.Equal(t, "0123456789abcdef0123456789abcdef01234567", event.HeadSHA())
+	assert.Zero(t, event.PRNumber())
+	assert.Empty(t, event.Actor())
+	assert.Nil(t, event.Repository())
+}
+
+func TestReadEventUnhandled(t *testing.T) {
+	t.Parallel()
+
+	env := map[string]string{
+		"GITHUB_EVENT_NAME": "deployment",
+		"GITHUB_EVENT_PATH": filepath.Join("..", "testdata", "push.json"),
+	}
+	action := githubactions.New(
+		githubactions.WithWriter(io.Discard),
+		githubactions.WithGetenv(func(key string) string { return env[key] }),
+	)
+
+	_, err := ReadEvent(action)
+	assert.EqualError(t, err, `unhandled event to unmarshal: "deployment"`)
+}
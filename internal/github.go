@@ -15,68 +15,65 @@
 package internal
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"os"
+	"net/http"
+	"time"
 
-	"github.com/FerretDB/gh"
 	"github.com/google/go-github/v70/github"
 	"github.com/sethvargo/go-githubactions"
+	"golang.org/x/oauth2"
 )
 
-// GitHubClient returns GitHub API client with token from the given environment variable.
-func GitHubClient(action *githubactions.Action, tokenVar string) *github.Client {
+// GitHubClient returns GitHub API client with token from the given environment variable,
+// or with GitHub App credentials from action inputs; see TokenSource.
+func GitHubClient(ctx context.Context, action *githubactions.Action, tokenVar string) (*github.Client, error) {
 	// without the token, our anonymous requests hit the rate limit too often
-	token := action.Getenv(tokenVar)
-	if token == "" {
-		action.Fatalf("%s is not set.", tokenVar)
-		return nil
+	ts, err := TokenSource(action, tokenVar)
+	if err != nil {
+		return nil, fmt.Errorf("GitHubClient: %w", err)
 	}
 
-	c, err := gh.NewRESTClient(token, action.Debugf)
-	if err != nil {
-		action.Fatalf("Failed to create GitHub client: %s.", err)
+	// don't use http.DefaultClient and oauth2.NewClient to avoid data races
+	httpClient := &http.Client{
+		Transport: &oauth2.Transport{
+			Base:   NewRateLimitTransport(NewTransport(http.DefaultTransport, action, action.Getenv(tokenVar)), action),
+			Source: oauth2.ReuseTokenSource(nil, ts),
+		},
 	}
 
-	return c
-}
+	c := github.NewClient(httpClient)
 
-// ReadEvent reads event from GITHUB_EVENT_PATH path.
-func ReadEvent(action *githubactions.Action) (any, error) {
-	eventPath := action.Getenv("GITHUB_EVENT_PATH")
-	if eventPath == "" {
-		return nil, fmt.Errorf("GITHUB_EVENT_PATH is not set")
-	}
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
 
-	b, err := os.ReadFile(eventPath)
+	// Query rate limit to check that the client is able to make queries.
+	// See https://docs.github.com/en/rest/rate-limit.
+	// We can't use https://docs.github.com/en/rest/users/users#get-the-authenticated-user API,
+	// because short-lived automatic GITHUB_TOKEN is provided by GitHub Actions App that can't access this API.
+	rl, _, err := c.RateLimit.Get(ctx)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("GitHubClient: %w", err)
 	}
 
-	// Debug level requires `ACTIONS_RUNNER_DEBUG` secret to be set to `true`:
-	// https://docs.github.com/en/actions/monitoring-and-troubleshooting-workflows/enabling-debug-logging
-	// Note that `pull_request` events from forks do not have access to secrets,
-	// so that line will not be logged in that case.
-	action.Debugf("Read event from %s:\n%s", eventPath, string(b))
+	action.Debugf(
+		"Rate limit: %d/%d, resets at: %s.",
+		rl.Core.Remaining, rl.Core.Limit, rl.Core.Reset.Format(time.RFC3339),
+	)
 
-	eventName := action.Getenv("GITHUB_EVENT_NAME")
-	if eventName == "" {
-		return nil, fmt.Errorf("GITHUB_EVENT_NAME is not set")
-	}
-
-	var event any
-	switch eventName {
-	case "pull_request", "pull_request_target":
-		event = new(github.PullRequestEvent)
-	case "push", "schedule":
-		event = new(github.PushEvent)
-	default:
-		return nil, fmt.Errorf("unhandled event to unmarshal: %q", eventName)
-	}
+	return c, nil
+}
 
-	if err := json.Unmarshal(b, event); err != nil {
-		return nil, err
+// IsForbidden returns true if the error is a GitHub API response with 403 status code,
+// like for the token lacking permissions (read-only GITHUB_TOKEN for PRs from forks).
+//
+// Rate limit errors with the same status code are handled by NewRateLimitTransport.
+func IsForbidden(err error) bool {
+	var githubErr *github.ErrorResponse
+	if !errors.As(err, &githubErr) || githubErr.Response == nil {
+		return false
 	}
 
-	return event, nil
+	return githubErr.Response.StatusCode == http.StatusForbidden
 }
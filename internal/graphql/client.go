@@ -16,6 +16,8 @@ package graphql
 
 import (
 	"context"
+	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/sethvargo/go-githubactions"
@@ -31,27 +33,48 @@ type Client struct {
 	action *githubactions.Action
 }
 
-// NewClient returns GitHub GraphQL API client with token from the given environment variable.
-func NewClient(ctx context.Context, action *githubactions.Action, tokenVar string) *Client {
-	token := action.Getenv(tokenVar)
-	if token == "" {
-		action.Fatalf("%s is not set.", tokenVar)
-		return nil
+// rateLimit is queried together with other fields to track queries cost;
+// see [internal.NewRateLimitTransport].
+type rateLimit struct {
+	Cost      githubv4.Int
+	Remaining githubv4.Int
+}
+
+// NewClient returns GitHub GraphQL API client with token from the given environment variable,
+// or with GitHub App credentials from action inputs; see [internal.TokenSource].
+//
+// The action is used only for logging.
+func NewClient(ctx context.Context, action *githubactions.Action, tokenVar string) (*Client, error) {
+	ts, err := internal.TokenSource(action, tokenVar)
+	if err != nil {
+		return nil, fmt.Errorf("NewClient: %w", &Error{Kind: ErrAuthentication, Err: err})
 	}
 
-	httpClient := oauth2.NewClient(ctx, oauth2.StaticTokenSource(
-		&oauth2.Token{AccessToken: token},
-	))
-	httpClient.Transport = internal.NewTransport(httpClient.Transport, action)
+	httpClient := oauth2.NewClient(ctx, ts)
+	httpClient.Transport = internal.NewRateLimitTransport(
+		internal.NewTransport(httpClient.Transport, action, action.Getenv(tokenVar)), action,
+	)
+
+	c, err := NewClientFromHTTP(ctx, action, httpClient)
+	if err != nil {
+		return nil, fmt.Errorf("NewClient: %w", err)
+	}
 
-	c := githubv4.NewClient(httpClient)
+	return c, nil
+}
+
+// NewClientFromHTTP returns GitHub GraphQL API client that uses the given HTTP client.
+//
+// The HTTP client should authenticate requests. It is used by tests to replay recorded responses.
+func NewClientFromHTTP(ctx context.Context, action *githubactions.Action, httpClient *http.Client) (*Client, error) {
+	c := &Client{
+		Client: githubv4.NewClient(httpClient),
+		action: action,
+	}
 
 	// Query rate limit to check that the client is able to make queries.
 	// See https://docs.github.com/en/graphql/overview/resource-limitations.
 	var rl struct {
-		Viewer struct {
-			Login githubv4.String
-		}
 		RateLimit struct {
 			Limit     githubv4.Int
 			Remaining githubv4.Int
@@ -60,17 +83,41 @@ func NewClient(ctx context.Context, action *githubactions.Action, tokenVar strin
 	}
 
 	if err := c.Query(ctx, &rl, nil); err != nil {
-		action.Fatalf("Failed to query rate limit: %s.", err)
-		return nil
+		return nil, fmt.Errorf("NewClientFromHTTP: %w", err)
+	}
+
+	// Viewer is not accessible with GitHub App installation tokens, and it is used only for logging.
+	var v struct {
+		Viewer struct {
+			Login githubv4.String
+		}
+	}
+
+	login := "unknown"
+	if err := c.Query(ctx, &v, nil); err != nil {
+		action.Infof("Failed to get user (expected for GitHub App installation tokens): %s.", err)
+	} else {
+		login = string(v.Viewer.Login)
 	}
 
 	action.Infof(
 		"User: %s, rate limit: %d/%d, resets at: %s.",
-		rl.Viewer.Login, rl.RateLimit.Remaining, rl.RateLimit.Limit, rl.RateLimit.ResetAt.Format(time.RFC3339),
+		login, rl.RateLimit.Remaining, rl.RateLimit.Limit, rl.RateLimit.ResetAt.Format(time.RFC3339),
 	)
 
-	return &Client{
-		Client: c,
-		action: action,
-	}
+	return c, nil
+}
+
+// Query executes a single GraphQL query request.
+//
+// Errors of known kinds are returned as [*Error].
+func (c *Client) Query(ctx context.Context, q any, variables map[string]any) error {
+	return classify(c.Client.Query(ctx, q, variables))
+}
+
+// Mutate executes a single GraphQL mutation request.
+//
+// Errors of known kinds are returned as [*Error].
+func (c *Client) Mutate(ctx context.Context, m any, input githubv4.Input, variables map[string]any) error {
+	return classify(c.Client.Mutate(ctx, m, input, variables))
 }
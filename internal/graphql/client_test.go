@@ -0,0 +1,42 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sethvargo/go-githubactions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/github-actions/internal/testutil"
+)
+
+func TestAppToken(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	// GitHub App installation tokens can't query viewer, but that should not prevent other queries.
+	c, err := NewClientFromHTTP(ctx, githubactions.New(), testutil.HTTPClient(t, "CONFORM_APP_TOKEN"))
+	require.NoError(t, err)
+
+	// https://github.com/FerretDB/github-actions/pull/85
+	actual, err := c.GetPullRequest(ctx, "PR_kwDOGfwnTc48u60R")
+	require.NoError(t, err)
+	assert.Equal(t, "Migrate to `ProjectV2`", actual.Title)
+	assert.Equal(t, []string{"code/chore", "trust"}, actual.Labels)
+}
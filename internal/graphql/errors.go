@@ -0,0 +1,96 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphql
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// Kinds of errors returned by Client.
+//
+// Use [errors.Is] to check for them, or [errors.As] with [*Error] to get the original error.
+var (
+	// ErrAuthentication is returned when the token is not set, invalid, or lacks permissions.
+	ErrAuthentication = errors.New("authentication failed")
+
+	// ErrRateLimited is returned when the primary or secondary rate limit is exceeded.
+	ErrRateLimited = errors.New("rate limited")
+
+	// ErrNotFound is returned when the requested node does not exist or is not visible.
+	ErrNotFound = errors.New("not found")
+
+	// ErrSchemaMismatch is returned when the query does not match the API schema.
+	ErrSchemaMismatch = errors.New("schema mismatch")
+)
+
+// Error is an error of a known kind returned by Client.
+type Error struct {
+	Kind error // one of Err* variables
+	Err  error // original error
+}
+
+// Error implements error interface.
+func (e *Error) Error() string {
+	return e.Kind.Error() + ": " + e.Err.Error()
+}
+
+// Unwrap returns both the kind and the original error, so [errors.Is] works for both.
+func (e *Error) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+// classify wraps githubv4 error into [*Error] if its kind is known.
+//
+// Errors of unknown kinds are returned as is.
+//
+// githubv4 does not expose HTTP status codes or GraphQL error types,
+// so error messages are used.
+func classify(err error) error {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+
+	var kind error
+
+	msg := strings.ToLower(err.Error())
+
+	switch {
+	case strings.Contains(msg, "rate limit"),
+		strings.HasPrefix(msg, "non-200 ok status code: 429"):
+		kind = ErrRateLimited
+
+	case strings.HasPrefix(msg, "non-200 ok status code: 401"),
+		strings.HasPrefix(msg, "non-200 ok status code: 403"),
+		strings.Contains(msg, "resource not accessible"),
+		strings.Contains(msg, "bad credentials"):
+		kind = ErrAuthentication
+
+	case strings.HasPrefix(msg, "non-200 ok status code: 404"),
+		strings.Contains(msg, "could not resolve to"):
+		kind = ErrNotFound
+
+	// the first case is returned by GitHub, the second by githubv4 for unexpected response data
+	case strings.Contains(msg, "doesn't exist on type"),
+		strings.Contains(msg, "doesn't exist in any of"):
+		kind = ErrSchemaMismatch
+
+	default:
+		return err
+	}
+
+	return &Error{Kind: kind, Err: err}
+}
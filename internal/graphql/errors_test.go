@@ -0,0 +1,121 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphql
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sethvargo/go-githubactions"
+	"github.com/shurcooL/githubv4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/github-actions/internal/testutil"
+)
+
+func TestErrors(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name     string
+		status   int
+		body     string
+		expected error // nil for unclassified errors
+	}{{
+		name:     "BadCredentials",
+		status:   http.StatusUnauthorized,
+		body:     `{"message": "Bad credentials"}`,
+		expected: ErrAuthentication,
+	}, {
+		name:     "SecondaryRateLimit",
+		status:   http.StatusForbidden,
+		body:     `{"message": "You have exceeded a secondary rate limit."}`,
+		expected: ErrRateLimited,
+	}, {
+		name:     "RateLimit",
+		status:   http.StatusOK,
+		body:     `{"errors": [{"type": "RATE_LIMITED", "message": "API rate limit exceeded for user ID 1."}]}`,
+		expected: ErrRateLimited,
+	}, {
+		name:     "NotFound",
+		status:   http.StatusOK,
+		body:     `{"data": {"node": null}, "errors": [{"message": "Could not resolve to a node with the global id of 'PR_1'"}]}`,
+		expected: ErrNotFound,
+	}, {
+		name:     "SchemaGitHub",
+		status:   http.StatusOK,
+		body:     `{"errors": [{"message": "Field 'closingIssuesReferences' doesn't exist on type 'PullRequest'"}]}`,
+		expected: ErrSchemaMismatch,
+	}, {
+		name:     "SchemaResponse",
+		status:   http.StatusOK,
+		body:     `{"data": {"node": {"unexpected": true}}}`,
+		expected: ErrSchemaMismatch,
+	}, {
+		name:   "Unknown",
+		status: http.StatusInternalServerError,
+		body:   `{"message": "Server Error"}`,
+	}}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tc.status)
+				w.Write([]byte(tc.body))
+			}))
+			t.Cleanup(srv.Close)
+
+			c := &Client{
+				Client: githubv4.NewEnterpriseClient(srv.URL, nil),
+				action: githubactions.New(),
+			}
+
+			_, err := c.GetPullRequest(context.Background(), "PR_1")
+			require.Error(t, err)
+
+			var e *Error
+			if tc.expected == nil {
+				assert.False(t, errors.As(err, &e), "%v", err)
+				return
+			}
+
+			assert.ErrorIs(t, err, tc.expected)
+			require.ErrorAs(t, err, &e)
+			assert.Equal(t, tc.expected, e.Kind)
+		})
+	}
+}
+
+func TestNewClientNoToken(t *testing.T) {
+	t.Parallel()
+
+	getenv := testutil.GetEnvFunc(t, map[string]string{
+		"INPUT_APP-ID":  "",
+		"CONFORM_TOKEN": "",
+	})
+	action := githubactions.New(githubactions.WithGetenv(getenv))
+
+	_, err := NewClient(context.Background(), action, "CONFORM_TOKEN")
+	assert.ErrorIs(t, err, ErrAuthentication)
+	assert.EqualError(t, err, "NewClient: authentication failed: CONFORM_TOKEN is not set")
+}
@@ -0,0 +1,116 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/shurcooL/githubv4"
+)
+
+// IsTeamMember returns true if the user is a member of the organization's team.
+//
+// It requires `read:org` token permission.
+func (c *Client) IsTeamMember(ctx context.Context, org, team, user string) (bool, error) {
+	// https://docs.github.com/en/graphql/reference/objects#team
+	var q struct {
+		Organization *struct {
+			Team *struct {
+				Members struct {
+					Nodes []struct {
+						Login githubv4.String
+					}
+				} `graphql:"members(query: $user, first: 100)"`
+			} `graphql:"team(slug: $team)"`
+		} `graphql:"organization(login: $org)"`
+
+		RateLimit rateLimit
+	}
+
+	variables := map[string]any{
+		"org":  githubv4.String(org),
+		"team": githubv4.String(team),
+		"user": githubv4.String(user),
+	}
+
+	if err := c.Query(ctx, &q, variables); err != nil {
+		return false, fmt.Errorf("IsTeamMember: %w", err)
+	}
+
+	if q.Organization == nil || q.Organization.Team == nil {
+		return false, fmt.Errorf("IsTeamMember: %w", &Error{Kind: ErrNotFound, Err: fmt.Errorf("team %s/%s", org, team)})
+	}
+
+	// query matches logins by substring, so check them all
+	for _, node := range q.Organization.Team.Members.Nodes {
+		if strings.EqualFold(string(node.Login), user) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// GetRepositoryPermission returns the user's permission level for the repository
+// (like "WRITE" or "ADMIN"), or empty string if the user is not a collaborator.
+//
+// It requires push access to the repository.
+func (c *Client) GetRepositoryPermission(ctx context.Context, owner, repo, user string) (string, error) {
+	// https://docs.github.com/en/graphql/reference/objects#repositorycollaboratorconnection
+	var q struct {
+		Repository *struct {
+			Collaborators *struct {
+				Edges []struct {
+					Permission githubv4.RepositoryPermission
+					Node       struct {
+						Login githubv4.String
+					}
+				}
+			} `graphql:"collaborators(query: $user, first: 100)"`
+		} `graphql:"repository(owner: $owner, name: $repo)"`
+
+		RateLimit rateLimit
+	}
+
+	variables := map[string]any{
+		"owner": githubv4.String(owner),
+		"repo":  githubv4.String(repo),
+		"user":  githubv4.String(user),
+	}
+
+	if err := c.Query(ctx, &q, variables); err != nil {
+		return "", fmt.Errorf("GetRepositoryPermission: %w", err)
+	}
+
+	if q.Repository == nil {
+		return "", fmt.Errorf("GetRepositoryPermission: %w", &Error{Kind: ErrNotFound, Err: fmt.Errorf("repository %s/%s", owner, repo)})
+	}
+
+	if q.Repository.Collaborators == nil {
+		err := fmt.Errorf("collaborators of %s/%s are not available", owner, repo)
+		return "", fmt.Errorf("GetRepositoryPermission: %w", &Error{Kind: ErrAuthentication, Err: err})
+	}
+
+	// query matches logins by substring, so check them all
+	for _, edge := range q.Repository.Collaborators.Edges {
+		if strings.EqualFold(string(edge.Node.Login), user) {
+			return string(edge.Permission), nil
+		}
+	}
+
+	return "", nil
+}
@@ -0,0 +1,137 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphql
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sethvargo/go-githubactions"
+	"github.com/shurcooL/githubv4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFixedClient returns a client for a local GraphQL API server that always responds with the given body.
+func newFixedClient(t *testing.T, body string) *Client {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+
+	return &Client{
+		Client: githubv4.NewEnterpriseClient(srv.URL, nil),
+		action: githubactions.New(),
+	}
+}
+
+func TestIsTeamMember(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name     string
+		body     string
+		expected bool
+		err      error
+	}{{
+		name:     "Member",
+		body:     `{"data": {"organization": {"team": {"members": {"nodes": [{"login": "aleksi2"}, {"login": "AlekSi"}]}}}}}`,
+		expected: true,
+	}, {
+		name: "Substring",
+		body: `{"data": {"organization": {"team": {"members": {"nodes": [{"login": "aleksi2"}]}}}}}`,
+	}, {
+		name: "NoTeam",
+		body: `{"data": {"organization": {"team": null}}}`,
+		err:  ErrNotFound,
+	}, {
+		name: "NoOrganization",
+		body: `{"data": {"organization": null}, "errors": [{"message": "Could not resolve to an Organization"}]}`,
+		err:  ErrNotFound,
+	}, {
+		name: "Forbidden",
+		body: `{"data": null, "errors": [{"message": "Resource not accessible by integration"}]}`,
+		err:  ErrAuthentication,
+	}}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := newFixedClient(t, tc.body)
+
+			actual, err := c.IsTeamMember(context.Background(), "FerretDB", "core", "AlekSi")
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestGetRepositoryPermission(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name     string
+		body     string
+		expected string
+		err      error
+	}{{
+		name: "Collaborator",
+		body: `{"data": {"repository": {"collaborators": {"edges": [` +
+			`{"permission": "READ", "node": {"login": "aleksi2"}}, {"permission": "ADMIN", "node": {"login": "aleksi"}}` +
+			`]}}}}`,
+		expected: "ADMIN",
+	}, {
+		name: "NotCollaborator",
+		body: `{"data": {"repository": {"collaborators": {"edges": [{"permission": "READ", "node": {"login": "aleksi2"}}]}}}}`,
+	}, {
+		name: "NoCollaborators",
+		body: `{"data": {"repository": {"collaborators": null}}}`,
+		err:  ErrAuthentication,
+	}, {
+		name: "NoRepository",
+		body: `{"data": {"repository": null}}`,
+		err:  ErrNotFound,
+	}}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := newFixedClient(t, tc.body)
+
+			actual, err := c.GetRepositoryPermission(context.Background(), "FerretDB", "FerretDB", "AlekSi")
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
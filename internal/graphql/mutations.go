@@ -0,0 +1,133 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/shurcooL/githubv4"
+)
+
+// UpdatePullRequest sets title and body of a pull request by GraphQL node ID.
+//
+// https://docs.github.com/en/graphql/reference/mutations#updatepullrequest
+func (c *Client) UpdatePullRequest(ctx context.Context, nodeID, title, body string) error {
+	var m struct {
+		UpdatePullRequest struct {
+			PullRequest struct {
+				ID githubv4.ID
+			}
+		} `graphql:"updatePullRequest(input: $input)"`
+	}
+
+	input := githubv4.UpdatePullRequestInput{
+		PullRequestID: githubv4.ID(nodeID),
+		Title:         githubv4.NewString(githubv4.String(title)),
+		Body:          githubv4.NewString(githubv4.String(body)),
+	}
+
+	if err := c.Mutate(ctx, &m, input, nil); err != nil {
+		return fmt.Errorf("UpdatePullRequest: %w", err)
+	}
+
+	return nil
+}
+
+// EnableAutoMerge enables auto-merge of a pull request by GraphQL node ID
+// with the repository's default merge method.
+//
+// https://docs.github.com/en/graphql/reference/mutations#enablepullrequestautomerge
+func (c *Client) EnableAutoMerge(ctx context.Context, nodeID string) error {
+	var m struct {
+		EnablePullRequestAutoMerge struct {
+			PullRequest struct {
+				ID githubv4.ID
+			}
+		} `graphql:"enablePullRequestAutoMerge(input: $input)"`
+	}
+
+	input := githubv4.EnablePullRequestAutoMergeInput{
+		PullRequestID: githubv4.ID(nodeID),
+	}
+
+	if err := c.Mutate(ctx, &m, input, nil); err != nil {
+		return fmt.Errorf("EnableAutoMerge: %w", err)
+	}
+
+	return nil
+}
+
+// ClearProjectField clears the field with the given name (like "Size")
+// in all projects of a pull request by GraphQL node ID.
+//
+// It returns titles of projects where the field was cleared.
+//
+// https://docs.github.com/en/graphql/reference/mutations#clearprojectv2itemfieldvalue
+func (c *Client) ClearProjectField(ctx context.Context, nodeID, field string) ([]string, error) {
+	var q struct {
+		Node struct {
+			PullRequest struct {
+				ProjectItems projectItems `graphql:"projectItems(first: 20)"`
+			} `graphql:"... on PullRequest"`
+		} `graphql:"node(id: $nodeID)"`
+
+		RateLimit rateLimit
+	}
+
+	variables := map[string]any{
+		"nodeID": githubv4.ID(nodeID),
+	}
+
+	if err := c.Query(ctx, &q, variables); err != nil {
+		return nil, fmt.Errorf("ClearProjectField: %w", err)
+	}
+
+	if err := c.fetchProjectItems(ctx, githubv4.ID(nodeID), &q.Node.PullRequest.ProjectItems); err != nil {
+		return nil, fmt.Errorf("ClearProjectField: %w", err)
+	}
+
+	var res []string
+
+	for _, item := range q.Node.PullRequest.ProjectItems.Nodes {
+		for _, value := range item.FieldValues.Nodes {
+			if string(value.Field.Name) != field {
+				continue
+			}
+
+			var m struct {
+				ClearProjectV2ItemFieldValue struct {
+					ProjectV2Item struct {
+						ID githubv4.ID
+					}
+				} `graphql:"clearProjectV2ItemFieldValue(input: $input)"`
+			}
+
+			input := githubv4.ClearProjectV2ItemFieldValueInput{
+				ProjectID: item.Project.ID,
+				ItemID:    item.ID,
+				FieldID:   value.Field.ID,
+			}
+
+			if err := c.Mutate(ctx, &m, input, nil); err != nil {
+				return res, fmt.Errorf("ClearProjectField: %w", err)
+			}
+
+			res = append(res, string(item.Project.Title))
+		}
+	}
+
+	return res, nil
+}
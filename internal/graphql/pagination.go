@@ -0,0 +1,236 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/shurcooL/githubv4"
+)
+
+// https://docs.github.com/en/graphql/reference/objects#pageinfo
+type pageInfo struct {
+	HasNextPage githubv4.Boolean
+	EndCursor   githubv4.String
+}
+
+// paginate calls next with the end cursor of the previous page until there are no more pages.
+//
+// The first page is expected to be already fetched as a part of a larger query;
+// next should fetch the page after the given cursor, append its nodes, and return its page info.
+func paginate(info pageInfo, next func(cursor githubv4.String) (pageInfo, error)) error {
+	for info.HasNextPage {
+		var err error
+		if info, err = next(info.EndCursor); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fetchLabels fetches remaining labels of a labelable node.
+func (c *Client) fetchLabels(ctx context.Context, nodeID githubv4.ID, l *labels) error {
+	err := paginate(l.PageInfo, func(cursor githubv4.String) (pageInfo, error) {
+		var q struct {
+			Node struct {
+				PullRequest struct {
+					Labels labels `graphql:"labels(first: 100, after: $cursor)"`
+				} `graphql:"... on PullRequest"`
+			} `graphql:"node(id: $nodeID)"`
+
+			RateLimit rateLimit
+		}
+
+		variables := map[string]any{
+			"nodeID": nodeID,
+			"cursor": cursor,
+		}
+
+		if err := c.Query(ctx, &q, variables); err != nil {
+			return pageInfo{}, err
+		}
+
+		l.Nodes = append(l.Nodes, q.Node.PullRequest.Labels.Nodes...)
+
+		return q.Node.PullRequest.Labels.PageInfo, nil
+	})
+	if err != nil {
+		return fmt.Errorf("fetchLabels: %w", err)
+	}
+
+	return nil
+}
+
+// fetchIssues fetches remaining closing issues references of a pull request,
+// and then all project items of each issue.
+func (c *Client) fetchIssues(ctx context.Context, nodeID githubv4.ID, is *issues) error {
+	err := paginate(is.PageInfo, func(cursor githubv4.String) (pageInfo, error) {
+		var q struct {
+			Node struct {
+				PullRequest struct {
+					ClosingIssuesReferences issues `graphql:"closingIssuesReferences(first: 100, after: $cursor)"`
+				} `graphql:"... on PullRequest"`
+			} `graphql:"node(id: $nodeID)"`
+
+			RateLimit rateLimit
+		}
+
+		variables := map[string]any{
+			"nodeID": nodeID,
+			"cursor": cursor,
+		}
+
+		if err := c.Query(ctx, &q, variables); err != nil {
+			return pageInfo{}, err
+		}
+
+		is.Nodes = append(is.Nodes, q.Node.PullRequest.ClosingIssuesReferences.Nodes...)
+
+		return q.Node.PullRequest.ClosingIssuesReferences.PageInfo, nil
+	})
+	if err != nil {
+		return fmt.Errorf("fetchIssues: %w", err)
+	}
+
+	for i := range is.Nodes {
+		if err = c.fetchProjectItems(ctx, is.Nodes[i].ID, &is.Nodes[i].ProjectItems); err != nil {
+			return fmt.Errorf("fetchIssues: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// fetchProjectItems fetches remaining project items of a pull request or issue,
+// and then all fields and field values of each item.
+func (c *Client) fetchProjectItems(ctx context.Context, nodeID githubv4.ID, items *projectItems) error {
+	err := paginate(items.PageInfo, func(cursor githubv4.String) (pageInfo, error) {
+		var q struct {
+			Node struct {
+				PullRequest struct {
+					ProjectItems projectItems `graphql:"projectItems(first: 100, after: $cursor)"`
+				} `graphql:"... on PullRequest"`
+				Issue struct {
+					ProjectItems projectItems `graphql:"projectItems(first: 100, after: $cursor)"`
+				} `graphql:"... on Issue"`
+			} `graphql:"node(id: $nodeID)"`
+
+			RateLimit rateLimit
+		}
+
+		variables := map[string]any{
+			"nodeID": nodeID,
+			"cursor": cursor,
+		}
+
+		if err := c.Query(ctx, &q, variables); err != nil {
+			return pageInfo{}, err
+		}
+
+		page := q.Node.PullRequest.ProjectItems
+		if page.Nodes == nil {
+			page = q.Node.Issue.ProjectItems
+		}
+
+		items.Nodes = append(items.Nodes, page.Nodes...)
+
+		return page.PageInfo, nil
+	})
+	if err != nil {
+		return fmt.Errorf("fetchProjectItems: %w", err)
+	}
+
+	for i := range items.Nodes {
+		item := &items.Nodes[i]
+
+		if err = c.fetchProjectFields(ctx, item.Project.ID, &item.Project.Fields); err != nil {
+			return fmt.Errorf("fetchProjectItems: %w", err)
+		}
+
+		if err = c.fetchProjectFieldValues(ctx, item.ID, &item.FieldValues); err != nil {
+			return fmt.Errorf("fetchProjectItems: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// fetchProjectFields fetches remaining fields of a project.
+func (c *Client) fetchProjectFields(ctx context.Context, projectID githubv4.ID, fields *projectFields) error {
+	err := paginate(fields.PageInfo, func(cursor githubv4.String) (pageInfo, error) {
+		var q struct {
+			Node struct {
+				ProjectV2 struct {
+					Fields projectFields `graphql:"fields(first: 100, after: $cursor)"`
+				} `graphql:"... on ProjectV2"`
+			} `graphql:"node(id: $nodeID)"`
+
+			RateLimit rateLimit
+		}
+
+		variables := map[string]any{
+			"nodeID": projectID,
+			"cursor": cursor,
+		}
+
+		if err := c.Query(ctx, &q, variables); err != nil {
+			return pageInfo{}, err
+		}
+
+		fields.Nodes = append(fields.Nodes, q.Node.ProjectV2.Fields.Nodes...)
+
+		return q.Node.ProjectV2.Fields.PageInfo, nil
+	})
+	if err != nil {
+		return fmt.Errorf("fetchProjectFields: %w", err)
+	}
+
+	return nil
+}
+
+// fetchProjectFieldValues fetches remaining field values of a project item.
+func (c *Client) fetchProjectFieldValues(ctx context.Context, itemID githubv4.ID, values *projectFieldValues) error {
+	err := paginate(values.PageInfo, func(cursor githubv4.String) (pageInfo, error) {
+		var q struct {
+			Node struct {
+				ProjectV2Item struct {
+					FieldValues projectFieldValues `graphql:"fieldValues(first: 100, after: $cursor)"`
+				} `graphql:"... on ProjectV2Item"`
+			} `graphql:"node(id: $nodeID)"`
+
+			RateLimit rateLimit
+		}
+
+		variables := map[string]any{
+			"nodeID": itemID,
+			"cursor": cursor,
+		}
+
+		if err := c.Query(ctx, &q, variables); err != nil {
+			return pageInfo{}, err
+		}
+
+		values.Nodes = append(values.Nodes, q.Node.ProjectV2Item.FieldValues.Nodes...)
+
+		return q.Node.ProjectV2Item.FieldValues.PageInfo, nil
+	})
+	if err != nil {
+		return fmt.Errorf("fetchProjectFieldValues: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,252 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sethvargo/go-githubactions"
+	"github.com/shurcooL/githubv4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubServer is a local GraphQL API server that returns canned responses.
+type stubServer struct {
+	t *testing.T
+
+	// responses maps connection, node ID, and cursor (see key) to response data
+	responses map[string]string
+
+	m    sync.Mutex
+	keys []string
+}
+
+// key returns a response key for the given query and variables.
+//
+// Follow-up queries are identified by the paginated connection;
+// initial queries have an empty cursor.
+func (s *stubServer) key(query string, variables map[string]any) string {
+	cursor, _ := variables["cursor"].(string)
+	if cursor == "" {
+		if strings.Contains(query, "closingIssuesReferences") {
+			return "initial closingIssuesReferences"
+		}
+
+		return "initial"
+	}
+
+	for _, conn := range []string{"labels", "closingIssuesReferences", "projectItems", "fieldValues", "fields"} {
+		if strings.Contains(query, conn+"(first: 100, after: $cursor)") {
+			return conn + " " + variables["nodeID"].(string) + " " + cursor
+		}
+	}
+
+	s.t.Fatalf("unexpected query %s", query)
+	panic("not reached")
+}
+
+// ServeHTTP implements http.Handler.
+func (s *stubServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Query     string         `json:"query"`
+		Variables map[string]any `json:"variables"`
+	}
+	require.NoError(s.t, json.NewDecoder(r.Body).Decode(&req))
+
+	key := s.key(req.Query, req.Variables)
+
+	s.m.Lock()
+	s.keys = append(s.keys, key)
+	s.m.Unlock()
+
+	data, ok := s.responses[key]
+	require.True(s.t, ok, "no response for %q", key)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"data":` + data + `}`))
+}
+
+func TestGetPullRequestPagination(t *testing.T) {
+	t.Parallel()
+
+	s := &stubServer{
+		t: t,
+		responses: map[string]string{
+			"initial": `{"node": {
+				"title": "Add pagination", "body": "Closes #5.", "closed": false,
+				"labels": {"nodes": [{"id": "LA_1", "name": "code/feature"}], "pageInfo": {"hasNextPage": true, "endCursor": "L1"}},
+				"autoMergeRequest": null,
+				"projectItems": {
+					"nodes": [{
+						"__typename": "ProjectV2Item", "id": "I1",
+						"project": {
+							"id": "P1", "title": "Project 1",
+							"fields": {
+								"nodes": [{"__typename": "ProjectV2IterationField", "id": "F_Sprint", "name": "Sprint"}],
+								"pageInfo": {"hasNextPage": true, "endCursor": "F1"}
+							}
+						},
+						"fieldValues": {
+							"nodes": [{"__typename": "ProjectV2ItemFieldSingleSelectValue", "field": {"name": "Status"}, "name": "Done"}],
+							"pageInfo": {"hasNextPage": true, "endCursor": "V1"}
+						}
+					}],
+					"pageInfo": {"hasNextPage": true, "endCursor": "PI1"}
+				}
+			}}`,
+			"labels PR_1 L1": `{"node": {
+				"labels": {"nodes": [{"id": "LA_2", "name": "trust"}], "pageInfo": {"hasNextPage": false, "endCursor": "L2"}}
+			}}`,
+			"projectItems PR_1 PI1": `{"node": {
+				"projectItems": {
+					"nodes": [{
+						"__typename": "ProjectV2Item", "id": "I2",
+						"project": {"id": "P2", "title": "Project 2", "fields": {"nodes": [], "pageInfo": {"hasNextPage": false}}},
+						"fieldValues": {"nodes": [], "pageInfo": {"hasNextPage": false}}
+					}],
+					"pageInfo": {"hasNextPage": false, "endCursor": "PI2"}
+				}
+			}}`,
+			"fields P1 F1": `{"node": {
+				"fields": {
+					"nodes": [{"__typename": "ProjectV2SingleSelectField", "id": "F_Size", "name": "Size"}],
+					"pageInfo": {"hasNextPage": false, "endCursor": "F2"}
+				}
+			}}`,
+			"fieldValues I1 V1": `{"node": {
+				"fieldValues": {
+					"nodes": [{"__typename": "ProjectV2ItemFieldIterationValue", "field": {"name": "Sprint"}, "title": "Sprint 1"}],
+					"pageInfo": {"hasNextPage": false, "endCursor": "V2"}
+				}
+			}}`,
+		},
+	}
+
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+
+	c := &Client{
+		Client: githubv4.NewEnterpriseClient(srv.URL, nil),
+		action: githubactions.New(),
+	}
+
+	expected := &PullRequest{
+		Title:  "Add pagination",
+		Body:   "Closes #5.",
+		Labels: []string{"code/feature", "trust"},
+		ProjectFields: map[string]Fields{
+			"Project 1": {
+				"Sprint": "Sprint 1",
+				"Status": "Done",
+			},
+			"Project 2": {},
+		},
+	}
+	actual, err := c.GetPullRequest(context.Background(), "PR_1")
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+
+	// linked issues are not queried
+	assert.Equal(t, []string{
+		"initial",
+		"labels PR_1 L1",
+		"projectItems PR_1 PI1",
+		"fields P1 F1",
+		"fieldValues I1 V1",
+	}, s.keys)
+}
+
+func TestGetLinkedIssuesPagination(t *testing.T) {
+	t.Parallel()
+
+	s := &stubServer{
+		t: t,
+		responses: map[string]string{
+			"initial closingIssuesReferences": `{"node": {
+				"closingIssuesReferences": {
+					"nodes": [{
+						"id": "ISS_1", "number": 5, "title": "Pagination", "closed": false,
+						"repository": {"nameWithOwner": "FerretDB/github-actions"},
+						"projectItems": {"nodes": [], "pageInfo": {"hasNextPage": true, "endCursor": "IP1"}}
+					}],
+					"pageInfo": {"hasNextPage": true, "endCursor": "C1"}
+				}
+			}}`,
+			"closingIssuesReferences PR_1 C1": `{"node": {
+				"closingIssuesReferences": {
+					"nodes": [{
+						"id": "ISS_2", "number": 6, "title": "Linked issues", "closed": true,
+						"repository": {"nameWithOwner": "FerretDB/FerretDB"},
+						"projectItems": {"nodes": [], "pageInfo": {"hasNextPage": false}}
+					}],
+					"pageInfo": {"hasNextPage": false, "endCursor": "C2"}
+				}
+			}}`,
+			"projectItems ISS_1 IP1": `{"node": {
+				"projectItems": {
+					"nodes": [{
+						"__typename": "ProjectV2Item", "id": "I3",
+						"project": {"id": "P1", "title": "Project 1", "fields": {"nodes": [], "pageInfo": {"hasNextPage": false}}},
+						"fieldValues": {
+							"nodes": [{"__typename": "ProjectV2ItemFieldSingleSelectValue", "field": {"name": "Size"}, "name": "M"}],
+							"pageInfo": {"hasNextPage": false}
+						}
+					}],
+					"pageInfo": {"hasNextPage": false, "endCursor": "IP2"}
+				}
+			}}`,
+		},
+	}
+
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+
+	c := &Client{
+		Client: githubv4.NewEnterpriseClient(srv.URL, nil),
+		action: githubactions.New(),
+	}
+
+	expected := []Issue{{
+		Number:     5,
+		Title:      "Pagination",
+		Repository: "FerretDB/github-actions",
+		ProjectFields: map[string]Fields{
+			"Project 1": {
+				"Size": "M",
+			},
+		},
+	}, {
+		Number:     6,
+		Title:      "Linked issues",
+		Repository: "FerretDB/FerretDB",
+		Closed:     true,
+	}}
+	actual, err := c.GetLinkedIssues(context.Background(), "PR_1")
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+
+	assert.Equal(t, []string{
+		"initial closingIssuesReferences",
+		"closingIssuesReferences PR_1 C1",
+		"projectItems ISS_1 IP1",
+	}, s.keys)
+}
@@ -17,6 +17,7 @@ package graphql
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 
 	"github.com/shurcooL/githubv4"
 )
@@ -38,6 +39,17 @@ type PullRequest struct {
 	ProjectFields map[string]Fields
 }
 
+// Issue contains information about an issue linked to a pull request; see [Client.GetLinkedIssues].
+type Issue struct {
+	Number     int
+	Title      string
+	Repository string // like "FerretDB/FerretDB"
+	Closed     bool
+
+	// ProjectFields maps project title to fields.
+	ProjectFields map[string]Fields
+}
+
 // https://docs.github.com/en/graphql/reference/interfaces#projectv2fieldcommon
 type projectV2FieldCommon struct {
 	ID       githubv4.ID
@@ -104,46 +116,90 @@ type pullRequest struct {
 	Closed githubv4.Boolean
 
 	// https://docs.github.com/en/graphql/reference/interfaces#labelable
-	Labels struct {
-		Nodes []struct {
-			ID   githubv4.ID
-			Name githubv4.String
-		}
-	} `graphql:"labels(first: 20)"`
+	Labels labels `graphql:"labels(first: 20)"`
 
 	// https://docs.github.com/en/graphql/reference/objects#automergerequest
 	AutoMergeRequest struct {
 		EnabledAt githubv4.DateTime
 	}
 
-	// https://docs.github.com/en/graphql/reference/objects#projectv2item
-	ProjectItems struct {
-		Nodes []struct {
-			Typename githubv4.String `graphql:"__typename"`
+	ProjectItems projectItems `graphql:"projectItems(first: 20)"`
+}
+
+// https://docs.github.com/en/graphql/reference/objects#labelconnection
+type labels struct {
+	Nodes []struct {
+		ID   githubv4.ID
+		Name githubv4.String
+	}
+	PageInfo pageInfo
+}
 
-			ID githubv4.ID
+// https://docs.github.com/en/graphql/reference/objects#issueconnection
+type issues struct {
+	Nodes    []issue
+	PageInfo pageInfo
+}
 
-			Project struct {
-				ID     githubv4.ID
-				Title  githubv4.String
-				Fields struct {
-					Nodes []projectField
-				} `graphql:"fields(first: 20)"`
-			}
+// https://docs.github.com/en/graphql/reference/objects#issue
+type issue struct {
+	ID     githubv4.ID
+	Number githubv4.Int
+	Title  githubv4.String
+	Closed githubv4.Boolean
 
-			FieldValues struct {
-				Nodes []projectFieldValue
-			} `graphql:"fieldValues(first: 20)"`
-		}
-	} `graphql:"projectItems(first: 20)"`
+	Repository struct {
+		NameWithOwner githubv4.String
+	}
+
+	ProjectItems projectItems `graphql:"projectItems(first: 20)"`
+}
+
+// https://docs.github.com/en/graphql/reference/objects#projectv2itemconnection
+type projectItems struct {
+	Nodes    []projectItem
+	PageInfo pageInfo
+}
+
+// https://docs.github.com/en/graphql/reference/objects#projectv2item
+type projectItem struct {
+	Typename githubv4.String `graphql:"__typename"`
+
+	ID githubv4.ID
+
+	Project struct {
+		ID     githubv4.ID
+		Title  githubv4.String
+		Fields projectFields `graphql:"fields(first: 20)"`
+	}
+
+	FieldValues projectFieldValues `graphql:"fieldValues(first: 20)"`
+}
+
+// https://docs.github.com/en/graphql/reference/objects#projectv2fieldconfigurationconnection
+type projectFields struct {
+	Nodes    []projectField
+	PageInfo pageInfo
+}
+
+// https://docs.github.com/en/graphql/reference/objects#projectv2itemfieldvalueconnection
+type projectFieldValues struct {
+	Nodes    []projectFieldValue
+	PageInfo pageInfo
 }
 
 // GetPullRequest returns information about a pull request by GraphQL node ID.
-func (c *Client) GetPullRequest(ctx context.Context, nodeID string) *PullRequest {
+//
+// All connections (labels, project items, their fields and values)
+// are fetched completely with follow-up queries.
+// Linked issues are not fetched; see [Client.GetLinkedIssues].
+func (c *Client) GetPullRequest(ctx context.Context, nodeID string) (*PullRequest, error) {
 	var q struct {
 		Node struct {
 			PullRequest pullRequest `graphql:"... on PullRequest"`
 		} `graphql:"node(id: $nodeID)"`
+
+		RateLimit rateLimit
 	}
 
 	variables := map[string]any{
@@ -151,42 +207,93 @@ func (c *Client) GetPullRequest(ctx context.Context, nodeID string) *PullRequest
 	}
 
 	if err := c.Query(ctx, &q, variables); err != nil {
-		c.action.Fatalf("Failed to query pull request: %s.", err)
-		return nil
+		return nil, fmt.Errorf("GetPullRequest: %w", err)
 	}
 
 	b, err := json.MarshalIndent(q, "", "  ")
 	if err != nil {
-		c.action.Fatalf("%s", err)
+		return nil, fmt.Errorf("GetPullRequest: %w", err)
 	}
 
 	c.action.Infof("Got:\n%s", b)
 
-	res := &PullRequest{
-		Title:  string(q.Node.PullRequest.Title),
-		Body:   string(q.Node.PullRequest.Body),
-		Closed: bool(q.Node.PullRequest.Closed),
+	pr := &q.Node.PullRequest
+
+	if err = c.fetchLabels(ctx, nodeID, &pr.Labels); err != nil {
+		return nil, fmt.Errorf("GetPullRequest: %w", err)
+	}
+
+	if err = c.fetchProjectItems(ctx, nodeID, &pr.ProjectItems); err != nil {
+		return nil, fmt.Errorf("GetPullRequest: %w", err)
 	}
 
-	labelNodes := q.Node.PullRequest.Labels.Nodes
-	if len(labelNodes) == 20 {
-		c.action.Fatalf("Too many Labels nodes.")
-		return nil
+	res := &PullRequest{
+		Title:  string(pr.Title),
+		Body:   string(pr.Body),
+		Closed: bool(pr.Closed),
 	}
 
-	for _, labelNode := range labelNodes {
+	for _, labelNode := range pr.Labels.Nodes {
 		res.Labels = append(res.Labels, string(labelNode.Name))
 	}
 
-	res.AutoMerge = !q.Node.PullRequest.AutoMergeRequest.EnabledAt.IsZero()
+	res.AutoMerge = !pr.AutoMergeRequest.EnabledAt.IsZero()
+
+	res.ProjectFields = convertProjectItems(pr.ProjectItems.Nodes)
+
+	return res, nil
+}
+
+// GetLinkedIssues returns issues that will be closed by a pull request with the given GraphQL node ID.
+//
+// Issues with their project items are expensive to query,
+// so they are fetched separately from [Client.GetPullRequest] only when needed.
+// All connections are fetched completely with follow-up queries.
+func (c *Client) GetLinkedIssues(ctx context.Context, nodeID string) ([]Issue, error) {
+	var q struct {
+		Node struct {
+			PullRequest struct {
+				ClosingIssuesReferences issues `graphql:"closingIssuesReferences(first: 20)"`
+			} `graphql:"... on PullRequest"`
+		} `graphql:"node(id: $nodeID)"`
 
-	itemNodes := q.Node.PullRequest.ProjectItems.Nodes
-	if len(itemNodes) == 20 {
-		c.action.Fatalf("Too many ProjectItems nodes.")
-		return nil
+		RateLimit rateLimit
 	}
 
-	for _, itemNode := range itemNodes {
+	variables := map[string]any{
+		"nodeID": githubv4.ID(nodeID),
+	}
+
+	if err := c.Query(ctx, &q, variables); err != nil {
+		return nil, fmt.Errorf("GetLinkedIssues: %w", err)
+	}
+
+	is := &q.Node.PullRequest.ClosingIssuesReferences
+
+	if err := c.fetchIssues(ctx, nodeID, is); err != nil {
+		return nil, fmt.Errorf("GetLinkedIssues: %w", err)
+	}
+
+	res := make([]Issue, 0, len(is.Nodes))
+
+	for _, issueNode := range is.Nodes {
+		res = append(res, Issue{
+			Number:        int(issueNode.Number),
+			Title:         string(issueNode.Title),
+			Repository:    string(issueNode.Repository.NameWithOwner),
+			Closed:        bool(issueNode.Closed),
+			ProjectFields: convertProjectItems(issueNode.ProjectItems.Nodes),
+		})
+	}
+
+	return res, nil
+}
+
+// convertProjectItems returns fields of project items, mapped by project title.
+func convertProjectItems(items []projectItem) map[string]Fields {
+	var res map[string]Fields
+
+	for _, itemNode := range items {
 		fields := make(Fields)
 
 		// checks if IterationField exists and initializes its key in map
@@ -197,13 +304,7 @@ func (c *Client) GetPullRequest(ctx context.Context, nodeID string) *PullRequest
 			}
 		}
 
-		valueNodes := itemNode.FieldValues.Nodes
-		if len(valueNodes) == 20 {
-			c.action.Fatalf("Too many ProjectItems.FieldValues nodes.")
-			return nil
-		}
-
-		for _, valueNode := range valueNodes {
+		for _, valueNode := range itemNode.FieldValues.Nodes {
 			switch valueNode.Typename {
 			// Get those values from the pull request itself instead.
 			// case "ProjectV2ItemFieldLabelValue":
@@ -223,10 +324,10 @@ func (c *Client) GetPullRequest(ctx context.Context, nodeID string) *PullRequest
 			}
 		}
 
-		if res.ProjectFields == nil {
-			res.ProjectFields = make(map[string]Fields)
+		if res == nil {
+			res = make(map[string]Fields)
 		}
-		res.ProjectFields[string(itemNode.Project.Title)] = fields
+		res[string(itemNode.Project.Title)] = fields
 	}
 
 	return res
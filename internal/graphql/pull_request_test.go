@@ -21,13 +21,17 @@ import (
 
 	"github.com/sethvargo/go-githubactions"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/github-actions/internal/testutil"
 )
 
 func TestPullRequest(t *testing.T) {
 	t.Parallel()
 
 	ctx := context.Background()
-	c := NewClient(ctx, githubactions.New(), "CONFORM_TOKEN")
+	c, err := NewClientFromHTTP(ctx, githubactions.New(), testutil.HTTPClient(t, "CONFORM_TOKEN"))
+	require.NoError(t, err)
 
 	// To get node ID from PR:
 	// curl https://api.github.com/repos/FerretDB/github-actions/pulls/83 | jq '.node_id'
@@ -42,7 +46,8 @@ func TestPullRequest(t *testing.T) {
 			Closed: true,
 			Labels: []string{"deps"},
 		}
-		actual := c.GetPullRequest(ctx, "PR_kwDOGfwnTc48nVkp")
+		actual, err := c.GetPullRequest(ctx, "PR_kwDOGfwnTc48nVkp")
+		require.NoError(t, err)
 		actual.Body, _, _ = strings.Cut(actual.Body, "\n")
 		assert.Equal(t, expected, actual)
 	})
@@ -67,7 +72,8 @@ func TestPullRequest(t *testing.T) {
 				},
 			},
 		}
-		actual := c.GetPullRequest(ctx, "PR_kwDOGfwnTc48u60R")
+		actual, err := c.GetPullRequest(ctx, "PR_kwDOGfwnTc48u60R")
+		require.NoError(t, err)
 		assert.Equal(t, expected, actual)
 	})
 
@@ -81,7 +87,8 @@ func TestPullRequest(t *testing.T) {
 			Labels:    []string{"not ready", "do not merge"},
 			AutoMerge: true,
 		}
-		actual := c.GetPullRequest(ctx, "PR_kwDOGfwnTc5DpH8i")
+		actual, err := c.GetPullRequest(ctx, "PR_kwDOGfwnTc5DpH8i")
+		require.NoError(t, err)
 		assert.Equal(t, expected, actual)
 	})
 }
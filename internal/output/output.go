@@ -0,0 +1,234 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package output renders step summaries, annotations, and outputs of our actions.
+//
+// Summaries use GitHub-flavored Markdown.
+// See https://docs.github.com/en/get-started/writing-on-github/working-with-advanced-formatting/organizing-information-with-tables
+// and https://docs.github.com/en/get-started/writing-on-github/working-with-advanced-formatting/organizing-information-with-collapsed-sections.
+//
+//nolint:lll // those URLs are long
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/sethvargo/go-githubactions"
+)
+
+// Table is a GitHub-flavored Markdown table.
+type Table struct {
+	header []string
+	rows   [][]string
+}
+
+// NewTable returns a new table with the given column headers.
+func NewTable(header ...string) *Table {
+	return &Table{
+		header: header,
+	}
+}
+
+// Add adds a row. Missing cells are left empty; extra cells are ignored.
+func (t *Table) Add(cells ...string) {
+	row := make([]string, len(t.header))
+	copy(row, cells)
+	t.rows = append(t.rows, row)
+}
+
+// Len returns the number of rows.
+func (t *Table) Len() int {
+	return len(t.rows)
+}
+
+// String returns the table in Markdown.
+func (t *Table) String() string {
+	var buf strings.Builder
+
+	writeRow := func(cells []string) {
+		buf.WriteString("|")
+
+		for _, c := range cells {
+			buf.WriteString(" ")
+			buf.WriteString(escapeCell(c))
+			buf.WriteString(" |")
+		}
+
+		buf.WriteString("\n")
+	}
+
+	writeRow(t.header)
+
+	buf.WriteString("|")
+	for range t.header {
+		buf.WriteString(" --- |")
+	}
+	buf.WriteString("\n")
+
+	for _, row := range t.rows {
+		writeRow(row)
+	}
+
+	return buf.String()
+}
+
+// escapeCell escapes pipes and line breaks that would break the table.
+func escapeCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\n", "<br />")
+
+	return s
+}
+
+// Details returns a collapsible section with the given summary line and Markdown body.
+//
+// If open is true, the section is expanded by default.
+func Details(summary, body string, open bool) string {
+	tag := "<details>"
+	if open {
+		tag = "<details open>"
+	}
+
+	return fmt.Sprintf("%s\n<summary>%s</summary>\n\n%s\n\n</details>\n", tag, summary, strings.TrimSpace(body))
+}
+
+// AddSummary appends Markdown to the step summary and logs it.
+func AddSummary(action *githubactions.Action, markdown string) {
+	action.AddStepSummary(markdown)
+	action.Infof("%s", markdown)
+}
+
+// Level is an annotation level.
+type Level int
+
+const (
+	// Notice is a notice annotation.
+	Notice Level = iota
+
+	// Warning is a warning annotation.
+	Warning
+
+	// Error is an error annotation.
+	Error
+)
+
+// Annotation is a message shown in the workflow run and, with a file, in the PR diff.
+//
+// See https://docs.github.com/en/actions/writing-workflows/choosing-what-your-workflow-does/workflow-commands-for-github-actions#setting-an-error-message.
+//
+//nolint:lll // that URL is long
+type Annotation struct {
+	Level   Level
+	Title   string
+	Message string
+
+	// optional location; line numbers start from 1
+	File    string
+	Line    int
+	EndLine int
+}
+
+// Annotate logs the annotation.
+func Annotate(action *githubactions.Action, a Annotation) {
+	fields := make(map[string]string)
+
+	if a.Title != "" {
+		fields["title"] = a.Title
+	}
+
+	if a.File != "" {
+		fields["file"] = a.File
+
+		if a.Line > 0 {
+			fields["line"] = strconv.Itoa(a.Line)
+		}
+
+		if a.EndLine > 0 {
+			fields["endLine"] = strconv.Itoa(a.EndLine)
+		}
+	}
+
+	if len(fields) > 0 {
+		action = action.WithFieldsMap(fields)
+	}
+
+	switch a.Level {
+	case Error:
+		action.Errorf("%s", a.Message)
+	case Warning:
+		action.Warningf("%s", a.Message)
+	default:
+		action.Noticef("%s", a.Message)
+	}
+}
+
+// SetJSON sets the output parameter to the value encoded as JSON.
+func SetJSON(action *githubactions.Action, name string, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("SetJSON: %w", err)
+	}
+
+	action.SetOutput(name, string(b))
+
+	return nil
+}
+
+// Result output and input names.
+const (
+	// ResultOutput is the name of the output parameter with the action's result encoded as JSON.
+	ResultOutput = "json"
+
+	// ResultFileInput is the name of the optional input with a file path for the action's result.
+	ResultFileInput = "json-file"
+)
+
+// SetResult sets the `json` output parameter to the action's result encoded as JSON,
+// so downstream steps could use it with `fromJSON`.
+//
+// If `json-file` input is set, the result is also written to that file;
+// a relative path is resolved against the workspace.
+func SetResult(action *githubactions.Action, v any) error {
+	if err := SetJSON(action, ResultOutput, v); err != nil {
+		return fmt.Errorf("SetResult: %w", err)
+	}
+
+	path := action.GetInput(ResultFileInput)
+	if path == "" {
+		return nil
+	}
+
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(action.Getenv("GITHUB_WORKSPACE"), path)
+	}
+
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("SetResult: %w", err)
+	}
+
+	if err = os.WriteFile(path, append(b, '\n'), 0o666); err != nil {
+		return fmt.Errorf("SetResult: %w", err)
+	}
+
+	action.Infof("Result written to %s.", path)
+
+	return nil
+}
@@ -0,0 +1,154 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sethvargo/go-githubactions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTable(t *testing.T) {
+	t.Parallel()
+
+	table := NewTable("Check", "Status")
+	assert.Equal(t, "| Check | Status |\n| --- | --- |\n", table.String())
+
+	table.Add("Labels", "✅")
+	table.Add("Title", "❌ Must not | end\nwith a dot.")
+	table.Add("Body")
+	assert.Equal(t, 3, table.Len())
+
+	expected := "" +
+		"| Check | Status |\n" +
+		"| --- | --- |\n" +
+		"| Labels | ✅ |\n" +
+		"| Title | ❌ Must not \\| end<br />with a dot. |\n" +
+		"| Body |  |\n"
+	assert.Equal(t, expected, table.String())
+}
+
+func TestDetails(t *testing.T) {
+	t.Parallel()
+
+	expected := "<details>\n<summary>Rate limits</summary>\n\n| A |\n| --- |\n\n</details>\n"
+	assert.Equal(t, expected, Details("Rate limits", "| A |\n| --- |\n", false))
+
+	expected = "<details open>\n<summary>#1 Fix</summary>\n\nText.\n\n</details>\n"
+	assert.Equal(t, expected, Details("#1 Fix", "\nText.", true))
+}
+
+func TestAnnotate(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name       string
+		annotation Annotation
+		expected   string
+	}{{
+		name:       "Notice",
+		annotation: Annotation{Message: "Detected."},
+		expected:   "::notice::Detected.\n",
+	}, {
+		name:       "Title",
+		annotation: Annotation{Level: Warning, Title: "Sprint", Message: "No sprint."},
+		expected:   "::warning title=Sprint::No sprint.\n",
+	}, {
+		name: "File",
+		annotation: Annotation{
+			Level:   Error,
+			Title:   "Config",
+			Message: "Unknown check.",
+			File:    ".github/conform-pr.yml",
+			Line:    3,
+			EndLine: 4,
+		},
+		expected: "::error endLine=4,file=.github/conform-pr.yml,line=3,title=Config::Unknown check.\n",
+	}}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var out strings.Builder
+			Annotate(githubactions.New(githubactions.WithWriter(&out)), tc.annotation)
+			assert.Equal(t, tc.expected, out.String())
+		})
+	}
+}
+
+func TestSetJSON(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	env := map[string]string{
+		"GITHUB_OUTPUT":       filepath.Join(dir, "output"),
+		"GITHUB_STEP_SUMMARY": filepath.Join(dir, "summary"),
+	}
+
+	var out strings.Builder
+	action := githubactions.New(
+		githubactions.WithWriter(&out),
+		githubactions.WithGetenv(func(key string) string { return env[key] }),
+	)
+
+	require.NoError(t, SetJSON(action, "json", map[string]any{"owner": "FerretDB", "number": 1}))
+	AddSummary(action, "Detected.\n")
+
+	b, err := os.ReadFile(env["GITHUB_OUTPUT"])
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "json<<")
+	assert.Contains(t, string(b), "\n"+`{"number":1,"owner":"FerretDB"}`+"\n")
+
+	b, err = os.ReadFile(env["GITHUB_STEP_SUMMARY"])
+	require.NoError(t, err)
+	assert.Equal(t, "Detected.\n\n", string(b))
+	assert.Equal(t, "Detected.\n\n", out.String())
+
+	assert.EqualError(t, SetJSON(action, "json", func() {}), "SetJSON: json: unsupported type: func()")
+}
+
+func TestSetResult(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	env := map[string]string{
+		"GITHUB_OUTPUT":    filepath.Join(dir, "output"),
+		"GITHUB_WORKSPACE": dir,
+		"INPUT_JSON-FILE":  "result.json",
+	}
+
+	action := githubactions.New(
+		githubactions.WithWriter(io.Discard),
+		githubactions.WithGetenv(func(key string) string { return env[key] }),
+	)
+
+	require.NoError(t, SetResult(action, map[string]any{"owner": "FerretDB", "number": 1}))
+
+	b, err := os.ReadFile(env["GITHUB_OUTPUT"])
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "\n"+`{"number":1,"owner":"FerretDB"}`+"\n")
+
+	b, err = os.ReadFile(filepath.Join(dir, "result.json"))
+	require.NoError(t, err)
+	assert.Equal(t, "{\n  \"number\": 1,\n  \"owner\": \"FerretDB\"\n}\n", string(b))
+}
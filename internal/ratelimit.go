@@ -0,0 +1,413 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sethvargo/go-githubactions"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+
+	"github.com/FerretDB/github-actions/internal/output"
+)
+
+const (
+	// maxRetries is the maximal number of retries for a single request.
+	maxRetries = 3
+
+	// maxWait is the maximal time to wait for a rate limit reset or backoff;
+	// longer waits fail the request.
+	maxWait = 15 * time.Minute
+
+	// secondaryWait is used for secondary rate limits without `Retry-After` header.
+	//
+	// See https://docs.github.com/en/rest/using-the-rest-api/best-practices-for-using-the-rest-api#handle-rate-limit-errors-appropriately.
+	//nolint:lll // that URL is long
+	secondaryWait = time.Minute
+)
+
+// resourceStats contains rate limit state and counters for a single resource like "core" or "graphql".
+type resourceStats struct {
+	requests  int
+	remaining int
+	limit     int
+	reset     time.Time
+
+	// first and last seen X-RateLimit-Used values in the current window
+	firstUsed int
+	lastUsed  int
+}
+
+// rateLimitCounters contains counters of rate limit transports.
+type rateLimitCounters struct {
+	m              sync.Mutex
+	resources      map[string]*resourceStats
+	retries        int
+	primaryWaits   int
+	secondaryWaits int
+	waited         time.Duration
+
+	// from `rateLimit { cost remaining }` of GraphQL queries
+	graphQLQueries   int
+	graphQLCost      int
+	graphQLRemaining int
+}
+
+// defaultRateLimitCounters is shared by all transports created by NewRateLimitTransport.
+var defaultRateLimitCounters = new(rateLimitCounters)
+
+// rateLimitTransport is an http.RoundTripper that handles GitHub API rate limits and server errors.
+type rateLimitTransport struct {
+	t      http.RoundTripper
+	action *githubactions.Action
+	stats  *rateLimitCounters
+
+	// for tests
+	sleep func(ctx context.Context, d time.Duration) error
+	now   func() time.Time
+}
+
+// NewRateLimitTransport returns a new http.RoundTripper that wraps the source with rate limits handling.
+//
+// It tracks `X-RateLimit-*` headers and `rateLimit` cost of GraphQL queries,
+// waits until reset when the primary rate limit is exceeded,
+// honors `Retry-After` header for secondary rate limits, and retries idempotent requests
+// (including GraphQL queries, but not mutations) on server errors with jittered exponential backoff.
+// GraphQL responses with `RATE_LIMITED` errors have 200 status code; they are retried too.
+//
+// Counters are shared by all returned transports; see AddRateLimitSummary.
+func NewRateLimitTransport(source http.RoundTripper, action *githubactions.Action) http.RoundTripper {
+	return &rateLimitTransport{
+		t:      source,
+		action: action,
+		stats:  defaultRateLimitCounters,
+		sleep:  sleep,
+		now:    time.Now,
+	}
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+
+		req.Body.Close()
+	}
+
+	idempotent := isIdempotent(req, body)
+
+	for attempt := 0; ; attempt++ {
+		r := req.Clone(req.Context())
+		if body != nil {
+			r.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		resp, err := t.t.RoundTrip(r)
+
+		var wait time.Duration
+		var reason string
+
+		switch {
+		case err != nil:
+			if !idempotent {
+				return nil, err
+			}
+
+			wait, reason = backoff(attempt), err.Error()
+
+		case resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests:
+			t.update(resp)
+			wait, reason = t.rateLimitWait(resp, resp.StatusCode == http.StatusTooManyRequests)
+
+		case resp.StatusCode >= 500 && idempotent:
+			t.update(resp)
+			wait, reason = backoff(attempt), resp.Status
+
+		default:
+			t.update(resp)
+
+			limited, readErr := t.updateGraphQL(r, resp)
+			if readErr != nil {
+				return nil, readErr
+			}
+
+			if !limited {
+				return resp, nil
+			}
+
+			wait, reason = t.rateLimitWait(resp, true)
+		}
+
+		if wait == 0 || attempt == maxRetries || wait > maxWait {
+			return resp, err
+		}
+
+		if resp != nil {
+			// drain to reuse the connection
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		t.action.Warningf("%s %s: %s, retrying in %s.", req.Method, req.URL.Path, reason, wait.Round(time.Second))
+
+		t.stats.m.Lock()
+		t.stats.retries++
+		t.stats.waited += wait
+		t.stats.m.Unlock()
+
+		if err = t.sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// rateLimitWait returns the time to wait before retrying for rate-limited responses,
+// or zero if that response is not caused by a rate limit.
+//
+// If limited is true, the response is known to be caused by a rate limit
+// (like for 429 status code or GraphQL `RATE_LIMITED` error) even without headers.
+func (t *rateLimitTransport) rateLimitWait(resp *http.Response, limited bool) (time.Duration, string) {
+	if s := resp.Header.Get("Retry-After"); s != "" {
+		// that header contains either seconds or HTTP date
+		wait := time.Second
+		if secs, err := strconv.Atoi(s); err == nil {
+			wait += time.Duration(secs) * time.Second
+		} else if date, err := http.ParseTime(s); err == nil {
+			wait += date.Sub(t.now())
+		}
+
+		if wait < time.Second {
+			wait = time.Second
+		}
+
+		t.stats.m.Lock()
+		t.stats.secondaryWaits++
+		t.stats.m.Unlock()
+
+		return wait, "secondary rate limit exceeded"
+	}
+
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		reset, _ := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+		wait := time.Unix(reset, 0).Sub(t.now()) + time.Second
+		if wait < time.Second {
+			wait = time.Second
+		}
+
+		t.stats.m.Lock()
+		t.stats.primaryWaits++
+		t.stats.m.Unlock()
+
+		return wait, "primary rate limit exceeded"
+	}
+
+	// secondary rate limits without Retry-After header are not distinguishable
+	// from permission errors by headers, so only known rate limits are handled
+	if limited {
+		t.stats.m.Lock()
+		t.stats.secondaryWaits++
+		t.stats.m.Unlock()
+
+		return secondaryWait, "secondary rate limit exceeded"
+	}
+
+	return 0, ""
+}
+
+// update updates rate limit counters from response headers.
+func (t *rateLimitTransport) update(resp *http.Response) {
+	h := resp.Header
+
+	resource := h.Get("X-RateLimit-Resource")
+	if resource == "" {
+		return
+	}
+
+	remaining, _ := strconv.Atoi(h.Get("X-RateLimit-Remaining"))
+	limit, _ := strconv.Atoi(h.Get("X-RateLimit-Limit"))
+	used, _ := strconv.Atoi(h.Get("X-RateLimit-Used"))
+	reset, _ := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64)
+
+	t.stats.m.Lock()
+	defer t.stats.m.Unlock()
+
+	if t.stats.resources == nil {
+		t.stats.resources = make(map[string]*resourceStats)
+	}
+
+	s := t.stats.resources[resource]
+	if s == nil {
+		s = &resourceStats{firstUsed: used}
+		t.stats.resources[resource] = s
+	}
+
+	// window was reset; count previous usage
+	if used < s.lastUsed {
+		s.firstUsed -= s.lastUsed
+	}
+
+	s.requests++
+	s.remaining = remaining
+	s.limit = limit
+	s.reset = time.Unix(reset, 0)
+	s.lastUsed = used
+}
+
+// updateGraphQL updates counters from `rateLimit` in successful GraphQL response body,
+// and returns true if the response contains `RATE_LIMITED` error.
+//
+// The response body is replaced, so it could be read again.
+func (t *rateLimitTransport) updateGraphQL(req *http.Request, resp *http.Response) (bool, error) {
+	if req.Method != http.MethodPost || !strings.HasSuffix(req.URL.Path, "/graphql") || resp.StatusCode != http.StatusOK {
+		return false, nil
+	}
+
+	b, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if err != nil {
+		return false, err
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(b))
+
+	var res struct {
+		Data struct {
+			RateLimit *struct {
+				Cost      int `json:"cost"`
+				Remaining int `json:"remaining"`
+			} `json:"rateLimit"`
+		} `json:"data"`
+		Errors []struct {
+			Type string `json:"type"`
+		} `json:"errors"`
+	}
+
+	// that's not our problem if the body is not a valid GraphQL response
+	_ = json.Unmarshal(b, &res)
+
+	if rl := res.Data.RateLimit; rl != nil {
+		t.stats.m.Lock()
+		t.stats.graphQLQueries++
+		t.stats.graphQLCost += rl.Cost
+		t.stats.graphQLRemaining = rl.Remaining
+		t.stats.m.Unlock()
+	}
+
+	for _, e := range res.Errors {
+		if e.Type == "RATE_LIMITED" {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// summary returns rate limit counters in Markdown, or empty string if there were no requests.
+func (s *rateLimitCounters) summary() string {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if len(s.resources) == 0 && s.retries == 0 && s.graphQLQueries == 0 {
+		return ""
+	}
+
+	table := output.NewTable("Resource", "Requests", "Used", "Remaining", "Resets at")
+
+	resources := maps.Keys(s.resources)
+	slices.Sort(resources)
+
+	for _, name := range resources {
+		r := s.resources[name]
+		table.Add(
+			name,
+			strconv.Itoa(r.requests),
+			strconv.Itoa(r.lastUsed-r.firstUsed),
+			fmt.Sprintf("%d/%d", r.remaining, r.limit),
+			r.reset.UTC().Format(time.RFC3339),
+		)
+	}
+
+	retries := fmt.Sprintf(
+		"Retries: %d (primary rate limit: %d, secondary rate limit: %d), waited %s.",
+		s.retries, s.primaryWaits, s.secondaryWaits, s.waited.Round(time.Second),
+	)
+
+	if s.graphQLQueries > 0 {
+		retries += fmt.Sprintf(
+			"\nGraphQL queries cost: %d point(s) for %d query(ies), %d point(s) remaining.",
+			s.graphQLCost, s.graphQLQueries, s.graphQLRemaining,
+		)
+	}
+
+	// expand only if rate limits slowed us down
+	return output.Details("GitHub API rate limits", table.String()+"\n"+retries, s.retries > 0)
+}
+
+// AddRateLimitSummary adds counters of all rate limit transports to the step summary.
+func AddRateLimitSummary(action *githubactions.Action) {
+	if summary := defaultRateLimitCounters.summary(); summary != "" {
+		output.AddSummary(action, summary)
+	}
+}
+
+// isIdempotent returns true if the request can be safely retried.
+//
+// GraphQL queries are idempotent, but mutations are not.
+func isIdempotent(req *http.Request, body []byte) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	case http.MethodPost:
+		return strings.HasSuffix(req.URL.Path, "/graphql") && !bytes.Contains(body, []byte(`"query":"mutation`))
+	default:
+		return false
+	}
+}
+
+// backoff returns jittered exponential backoff duration for the given attempt (starting from 0).
+func backoff(attempt int) time.Duration {
+	d := time.Second << attempt
+	return d + time.Duration(rand.Int63n(int64(d)))
+}
+
+// sleep waits for the given duration or until the context is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,325 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-githubactions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitTransport(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2023, 10, 17, 12, 0, 0, 0, time.UTC)
+	reset := strconv.FormatInt(now.Add(10*time.Second).Unix(), 10)
+
+	// response is a canned response for the stub server
+	type response struct {
+		status int
+		header map[string]string
+		body   string
+	}
+
+	graphQLLimited := response{
+		status: http.StatusOK,
+		header: map[string]string{
+			"X-RateLimit-Resource":  "graphql",
+			"X-RateLimit-Remaining": "0",
+			"X-RateLimit-Limit":     "5000",
+			"X-RateLimit-Used":      "5000",
+			"X-RateLimit-Reset":     reset,
+		},
+		body: `{"errors":[{"type":"RATE_LIMITED","message":"API rate limit exceeded for user ID 1."}]}`,
+	}
+
+	ok := response{
+		status: http.StatusOK,
+		header: map[string]string{
+			"X-RateLimit-Resource":  "core",
+			"X-RateLimit-Remaining": "4990",
+			"X-RateLimit-Limit":     "5000",
+			"X-RateLimit-Used":      "10",
+			"X-RateLimit-Reset":     reset,
+		},
+	}
+
+	cases := []struct {
+		name          string
+		method        string
+		path          string
+		body          string
+		responses     []response
+		expectedCode  int
+		expectedSleep []time.Duration // nil means no retries; backoff durations are checked by range
+		expectedCost  int             // GraphQL queries cost
+	}{{
+		name:         "OK",
+		method:       http.MethodGet,
+		path:         "/repos/FerretDB/FerretDB",
+		responses:    []response{ok},
+		expectedCode: http.StatusOK,
+	}, {
+		name:   "ServerError",
+		method: http.MethodGet,
+		path:   "/repos/FerretDB/FerretDB",
+		responses: []response{
+			{status: http.StatusBadGateway},
+			{status: http.StatusServiceUnavailable},
+			ok,
+		},
+		expectedCode:  http.StatusOK,
+		expectedSleep: []time.Duration{time.Second, 2 * time.Second},
+	}, {
+		name:   "ServerErrorTooMany",
+		method: http.MethodGet,
+		path:   "/repos/FerretDB/FerretDB",
+		responses: []response{
+			{status: http.StatusBadGateway},
+			{status: http.StatusBadGateway},
+			{status: http.StatusBadGateway},
+			{status: http.StatusBadGateway},
+		},
+		expectedCode:  http.StatusBadGateway,
+		expectedSleep: []time.Duration{time.Second, 2 * time.Second, 4 * time.Second},
+	}, {
+		name:   "GraphQLQuery",
+		method: http.MethodPost,
+		path:   "/graphql",
+		body:   `{"query":"query($nodeID:ID!){node(id: $nodeID){id}}"}`,
+		responses: []response{
+			{status: http.StatusBadGateway},
+			ok,
+		},
+		expectedCode:  http.StatusOK,
+		expectedSleep: []time.Duration{time.Second},
+	}, {
+		name:   "GraphQLMutation",
+		method: http.MethodPost,
+		path:   "/graphql",
+		body:   `{"query":"mutation($input:UpdatePullRequestInput!){updatePullRequest(input: $input){pullRequest{id}}}"}`,
+		responses: []response{
+			{status: http.StatusBadGateway},
+		},
+		expectedCode: http.StatusBadGateway,
+	}, {
+		name:   "Primary",
+		method: http.MethodPost,
+		path:   "/repos/FerretDB/FerretDB/issues/1/comments",
+		body:   `{"body":"test"}`,
+		responses: []response{
+			{
+				status: http.StatusForbidden,
+				header: map[string]string{
+					"X-RateLimit-Resource":  "core",
+					"X-RateLimit-Remaining": "0",
+					"X-RateLimit-Limit":     "5000",
+					"X-RateLimit-Used":      "5000",
+					"X-RateLimit-Reset":     reset,
+				},
+			},
+			ok,
+		},
+		expectedCode:  http.StatusOK,
+		expectedSleep: []time.Duration{11 * time.Second},
+	}, {
+		name:   "Secondary",
+		method: http.MethodGet,
+		path:   "/repos/FerretDB/FerretDB",
+		responses: []response{
+			{status: http.StatusForbidden, header: map[string]string{"Retry-After": "30"}},
+			ok,
+		},
+		expectedCode:  http.StatusOK,
+		expectedSleep: []time.Duration{31 * time.Second},
+	}, {
+		name:   "SecondaryDate",
+		method: http.MethodGet,
+		path:   "/repos/FerretDB/FerretDB",
+		responses: []response{
+			{
+				status: http.StatusTooManyRequests,
+				header: map[string]string{"Retry-After": now.Add(30 * time.Second).Format(http.TimeFormat)},
+			},
+			ok,
+		},
+		expectedCode:  http.StatusOK,
+		expectedSleep: []time.Duration{31 * time.Second},
+	}, {
+		name:   "GraphQLCost",
+		method: http.MethodPost,
+		path:   "/graphql",
+		body:   `{"query":"query($nodeID:ID!){node(id: $nodeID){id},rateLimit{cost,remaining}}"}`,
+		responses: []response{
+			{status: http.StatusOK, body: `{"data":{"node":{"id":"PR_1"},"rateLimit":{"cost":3,"remaining":4997}}}`},
+		},
+		expectedCode: http.StatusOK,
+		expectedCost: 3,
+	}, {
+		name:   "GraphQLRateLimited",
+		method: http.MethodPost,
+		path:   "/graphql",
+		body:   `{"query":"query($nodeID:ID!){node(id: $nodeID){id},rateLimit{cost,remaining}}"}`,
+		responses: []response{
+			graphQLLimited,
+			{status: http.StatusOK, body: `{"data":{"node":{"id":"PR_1"},"rateLimit":{"cost":1,"remaining":4999}}}`},
+		},
+		expectedCode:  http.StatusOK,
+		expectedSleep: []time.Duration{11 * time.Second},
+		expectedCost:  1,
+	}, {
+		name:   "GraphQLMutationRateLimited",
+		method: http.MethodPost,
+		path:   "/graphql",
+		body:   `{"query":"mutation($input:UpdatePullRequestInput!){updatePullRequest(input: $input){pullRequest{id}}}"}`,
+		responses: []response{
+			graphQLLimited,
+			{status: http.StatusOK, body: `{"data":{"updatePullRequest":{"pullRequest":{"id":"PR_1"}}}}`},
+		},
+		expectedCode:  http.StatusOK,
+		expectedSleep: []time.Duration{11 * time.Second},
+	}, {
+		name:   "GraphQLRateLimitedTooMany",
+		method: http.MethodPost,
+		path:   "/graphql",
+		body:   `{"query":"query($nodeID:ID!){node(id: $nodeID){id}}"}`,
+		responses: []response{
+			graphQLLimited,
+			graphQLLimited,
+			graphQLLimited,
+			graphQLLimited,
+		},
+		expectedCode:  http.StatusOK,
+		expectedSleep: []time.Duration{11 * time.Second, 11 * time.Second, 11 * time.Second},
+	}, {
+		name:   "Forbidden",
+		method: http.MethodGet,
+		path:   "/repos/FerretDB/FerretDB",
+		responses: []response{
+			{status: http.StatusForbidden},
+		},
+		expectedCode: http.StatusForbidden,
+	}}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var requests int
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, _ := io.ReadAll(r.Body)
+				assert.Equal(t, tc.body, string(b))
+
+				require.Less(t, requests, len(tc.responses))
+				resp := tc.responses[requests]
+				requests++
+
+				for k, v := range resp.header {
+					w.Header().Set(k, v)
+				}
+				w.WriteHeader(resp.status)
+				w.Write([]byte(resp.body))
+			}))
+			t.Cleanup(srv.Close)
+
+			var slept []time.Duration
+			rt := &rateLimitTransport{
+				t:      http.DefaultTransport,
+				action: githubactions.New(githubactions.WithWriter(io.Discard)),
+				stats:  new(rateLimitCounters),
+				sleep: func(_ context.Context, d time.Duration) error {
+					slept = append(slept, d)
+					return nil
+				},
+				now: func() time.Time { return now },
+			}
+
+			req, err := http.NewRequest(tc.method, srv.URL+tc.path, strings.NewReader(tc.body))
+			require.NoError(t, err)
+
+			resp, err := rt.RoundTrip(req)
+			require.NoError(t, err)
+
+			// body is still readable after inspection
+			b, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, tc.responses[len(tc.responses)-1].body, string(b))
+
+			assert.Equal(t, tc.expectedCode, resp.StatusCode)
+			assert.Equal(t, len(tc.responses), requests)
+			require.Len(t, slept, len(tc.expectedSleep))
+
+			for i, expected := range tc.expectedSleep {
+				if tc.responses[i].status >= 500 {
+					// jittered backoff
+					assert.GreaterOrEqual(t, slept[i], expected)
+					assert.Less(t, slept[i], 2*expected)
+					continue
+				}
+
+				assert.Equal(t, expected, slept[i])
+			}
+
+			assert.Equal(t, len(tc.expectedSleep), rt.stats.retries)
+			assert.Equal(t, tc.expectedCost, rt.stats.graphQLCost)
+		})
+	}
+}
+
+func TestRateLimitSummary(t *testing.T) {
+	t.Parallel()
+
+	rt := &rateLimitTransport{
+		stats: new(rateLimitCounters),
+	}
+
+	assert.Empty(t, rt.stats.summary())
+
+	for _, used := range []string{"10", "15", "2"} {
+		rt.update(&http.Response{Header: http.Header{
+			"X-Ratelimit-Resource":  []string{"graphql"},
+			"X-Ratelimit-Remaining": []string{"4998"},
+			"X-Ratelimit-Limit":     []string{"5000"},
+			"X-Ratelimit-Used":      []string{used},
+			"X-Ratelimit-Reset":     []string{"1697544000"},
+		}})
+	}
+
+	rt.stats.retries = 1
+	rt.stats.secondaryWaits = 1
+	rt.stats.waited = 31 * time.Second
+	rt.stats.graphQLQueries = 2
+	rt.stats.graphQLCost = 5
+	rt.stats.graphQLRemaining = 4995
+
+	expected := "<details open>\n<summary>GitHub API rate limits</summary>\n\n" +
+		"| Resource | Requests | Used | Remaining | Resets at |\n" +
+		"| --- | --- | --- | --- | --- |\n" +
+		"| graphql | 3 | 7 | 4998/5000 | 2023-10-17T12:00:00Z |\n" +
+		"\nRetries: 1 (primary rate limit: 0, secondary rate limit: 1), waited 31s.\n" +
+		"GraphQL queries cost: 5 point(s) for 2 query(ies), 4995 point(s) remaining.\n\n</details>\n"
+	assert.Equal(t, expected, rt.stats.summary())
+}
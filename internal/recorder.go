@@ -0,0 +1,213 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// redacted replaces secrets in recordings.
+const redacted = "REDACTED"
+
+// recordedHeaders are response headers stored in recordings; others are dropped.
+var recordedHeaders = []string{"Content-Type", "Link"}
+
+// nameRegexp matches characters replaced in recording file names.
+var nameRegexp = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// recording is a request/response pair stored in a file.
+type recording struct {
+	Request struct {
+		Method string `json:"method"`
+		URL    string `json:"url"`
+		Body   string `json:"body"`
+	} `json:"request"`
+
+	Response struct {
+		StatusCode int         `json:"status_code"`
+		Header     http.Header `json:"header"`
+		Body       string      `json:"body"`
+	} `json:"response"`
+}
+
+// recorder is an http.RoundTripper that records responses to files, or replays them.
+type recorder struct {
+	t       http.RoundTripper
+	dir     string
+	record  bool
+	secrets []string
+	m       sync.Mutex
+}
+
+// NewRecorder returns a new http.RoundTripper that records and replays HTTP interactions
+// as JSON files in the given directory.
+//
+// In record mode, requests are sent with the source, and responses are stored
+// with the given secrets replaced. Request headers are never stored.
+// In replay mode, the source is not used, and stored responses are returned for matching requests
+// (same method, URL, and body); requests without recordings fail.
+func NewRecorder(source http.RoundTripper, dir string, record bool, secrets ...string) http.RoundTripper {
+	var s []string
+	for _, secret := range secrets {
+		if secret != "" {
+			s = append(s, secret)
+		}
+	}
+
+	return &recorder{
+		t:       source,
+		dir:     dir,
+		record:  record,
+		secrets: s,
+	}
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (r *recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, fmt.Errorf("recorder: %w", err)
+		}
+
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	var rec recording
+	rec.Request.Method = req.Method
+	rec.Request.URL = req.URL.String()
+	rec.Request.Body = r.scrub(string(body))
+
+	file := filepath.Join(r.dir, r.fileName(&rec))
+
+	if r.record {
+		return r.doRecord(req, &rec, file)
+	}
+
+	b, err := os.ReadFile(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("recorder: no recording for %s %s (%s)", req.Method, req.URL, file)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("recorder: %w", err)
+	}
+
+	if err = json.Unmarshal(b, &rec); err != nil {
+		return nil, fmt.Errorf("recorder: %s: %w", file, err)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rec.Response.StatusCode, http.StatusText(rec.Response.StatusCode)),
+		StatusCode:    rec.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        rec.Response.Header,
+		Body:          io.NopCloser(strings.NewReader(rec.Response.Body)),
+		ContentLength: int64(len(rec.Response.Body)),
+		Request:       req,
+	}, nil
+}
+
+// doRecord sends the request and stores the response to the file.
+func (r *recorder) doRecord(req *http.Request, rec *recording, file string) (*http.Response, error) {
+	resp, err := r.t.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if err != nil {
+		return nil, fmt.Errorf("recorder: %w", err)
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(b))
+
+	rec.Response.StatusCode = resp.StatusCode
+	rec.Response.Header = make(http.Header)
+	rec.Response.Body = r.scrub(string(b))
+
+	for _, h := range recordedHeaders {
+		if v := resp.Header.Values(h); v != nil {
+			rec.Response.Header[h] = v
+		}
+	}
+
+	if b, err = json.MarshalIndent(rec, "", "  "); err != nil {
+		return nil, fmt.Errorf("recorder: %w", err)
+	}
+
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	if err = os.MkdirAll(r.dir, 0o777); err != nil {
+		return nil, fmt.Errorf("recorder: %w", err)
+	}
+
+	if err = os.WriteFile(file, append(b, '\n'), 0o666); err != nil {
+		return nil, fmt.Errorf("recorder: %w", err)
+	}
+
+	return resp, nil
+}
+
+// fileName returns recording file name for the (scrubbed) request.
+//
+// It is readable and unique for method, URL, and body.
+func (r *recorder) fileName(rec *recording) string {
+	h := sha256.New()
+	h.Write([]byte(rec.Request.Method + " " + rec.Request.URL + "\n" + rec.Request.Body))
+	hash := hex.EncodeToString(h.Sum(nil))[:16]
+
+	path := rec.Request.URL
+	if _, p, ok := strings.Cut(path, "://"); ok {
+		_, path, _ = strings.Cut(p, "/")
+	}
+
+	path = strings.Trim(nameRegexp.ReplaceAllString(path, "_"), "_")
+	if len(path) > 60 {
+		path = path[:60]
+	}
+
+	return fmt.Sprintf("%s_%s_%s.json", rec.Request.Method, path, hash)
+}
+
+// scrub replaces secrets in s.
+func (r *recorder) scrub(s string) string {
+	for _, secret := range r.secrets {
+		s = strings.ReplaceAll(s, secret, redacted)
+	}
+
+	return s
+}
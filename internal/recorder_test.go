@@ -0,0 +1,101 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder(t *testing.T) {
+	t.Parallel()
+
+	const secret = "ghp_secret"
+
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		b, _ := io.ReadAll(r.Body)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Github-Request-Id", "ABCD")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"token": "` + secret + `", "request": "` + string(b) + `"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	dir := t.TempDir()
+
+	do := func(t *testing.T, rt http.RoundTripper, body string) (*http.Response, string, error) {
+		t.Helper()
+
+		req, err := http.NewRequest(http.MethodPost, srv.URL+"/graphql", strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+secret)
+
+		resp, err := rt.RoundTrip(req)
+		if err != nil {
+			return nil, "", err
+		}
+
+		defer resp.Body.Close()
+
+		b, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		return resp, string(b), nil
+	}
+
+	expectedBody := `{"token": "` + secret + `", "request": "query"}`
+
+	resp, body, err := do(t, NewRecorder(http.DefaultTransport, dir, true, secret, ""), "query")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, expectedBody, body)
+	assert.Equal(t, 1, requests)
+
+	files, err := filepath.Glob(filepath.Join(dir, "POST_graphql_*.json"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	b, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	assert.NotContains(t, string(b), secret)
+	assert.NotContains(t, string(b), "Authorization")
+	assert.NotContains(t, string(b), "X-Github-Request-Id")
+
+	replay := NewRecorder(nil, dir, false)
+
+	resp, body, err = do(t, replay, "query")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	assert.Equal(t, `{"token": "REDACTED", "request": "query"}`, body)
+	assert.Equal(t, 1, requests)
+
+	_, _, err = do(t, replay, "other query")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "recorder: no recording for POST "+srv.URL+"/graphql")
+	assert.Equal(t, 1, requests)
+}
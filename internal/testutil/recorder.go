@@ -0,0 +1,81 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutil
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v70/github"
+	"github.com/sethvargo/go-githubactions"
+	"golang.org/x/oauth2"
+
+	"github.com/FerretDB/github-actions/internal"
+)
+
+// RecordEnv is the environment variable that enables record mode when set to `true`.
+const RecordEnv = "RECORD_HTTP"
+
+// HTTPClient returns an authenticated HTTP client that replays GitHub API responses
+// recorded in the package's testdata/recordings/<TestName> directory.
+//
+// Requests are never sent to GitHub in replay mode, and the test fails if there are no recordings.
+// With RECORD_HTTP=true, requests are sent to GitHub with the token from the given environment variable,
+// and responses are recorded; the token is scrubbed from recordings.
+func HTTPClient(t testing.TB, tokenVar string) *http.Client {
+	t.Helper()
+
+	name, _, _ := strings.Cut(t.Name(), "/")
+	dir := filepath.Join("testdata", "recordings", name)
+
+	var transport http.RoundTripper
+	var token string
+
+	if os.Getenv(RecordEnv) == "true" {
+		if token = os.Getenv(tokenVar); token == "" {
+			t.Fatalf("%s is not set, it is required for recording.", tokenVar)
+		}
+
+		t.Logf("Recording to %s.", dir)
+		transport = internal.NewRecorder(http.DefaultTransport, dir, true, token)
+	} else {
+		if _, err := os.Stat(dir); err != nil {
+			t.Fatalf("No recordings in %s; run tests with %s=true and %s set to record them: %s.", dir, RecordEnv, tokenVar, err)
+		}
+
+		// replayed requests are not sent, so any token works
+		token = "replay"
+		transport = internal.NewRecorder(http.DefaultTransport, dir, false, token)
+	}
+
+	transport = internal.NewTransport(transport, githubactions.New(), token)
+
+	return &http.Client{
+		Transport: &oauth2.Transport{
+			Source: oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token}),
+			Base:   transport,
+		},
+	}
+}
+
+// GitHubClient returns GitHub REST API client that uses HTTPClient.
+func GitHubClient(t testing.TB, tokenVar string) *github.Client {
+	t.Helper()
+
+	return github.NewClient(HTTPClient(t, tokenVar))
+}
@@ -15,48 +15,232 @@
 package internal
 
 import (
+	"bytes"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httputil"
+	"net/url"
+	"regexp"
+	"strings"
 
 	"github.com/sethvargo/go-githubactions"
+	"golang.org/x/exp/slices"
 )
 
+// maxDumpBody is the maximal size of logged request and response bodies.
+const maxDumpBody = 16 * 1024
+
+// secretVars contains environment variables with secrets that should never be logged.
+var secretVars = []string{
+	"GITHUB_TOKEN",
+	"CONFORM_TOKEN",
+	"ACTIONS_RUNTIME_TOKEN",
+	"ACTIONS_ID_TOKEN_REQUEST_TOKEN",
+}
+
+// secretHeaders contains HTTP headers with secrets.
+var secretHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+}
+
+// secretParams contains URL query parameters with secrets.
+var secretParams = []string{
+	"access_token",
+	"client_secret",
+	"code",
+	"jwt",
+	"token",
+}
+
+// tokenRegexp matches GitHub tokens.
+//
+// See https://github.blog/engineering/platform-security/behind-githubs-new-authentication-token-formats/.
+var tokenRegexp = regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)
+
 // transport is an http.RoundTripper with debug logging.
 type transport struct {
-	t      http.RoundTripper
-	action *githubactions.Action
+	t       http.RoundTripper
+	action  *githubactions.Action
+	secrets []string
 }
 
 // NewTransport returns a new http.RoundTripper that wraps the source with debug logging.
-func NewTransport(source http.RoundTripper, action *githubactions.Action) http.RoundTripper {
-	return &transport{
+//
+// Logged requests and responses have secrets redacted: authentication headers,
+// tokens in URLs, GitHub tokens, values of known secret environment variables, and given secrets.
+// Those values are also masked in the action's log.
+// Large bodies are truncated.
+func NewTransport(source http.RoundTripper, action *githubactions.Action, secrets ...string) http.RoundTripper {
+	t := &transport{
 		t:      source,
 		action: action,
 	}
+
+	for _, v := range secretVars {
+		secrets = append(secrets, action.Getenv(v))
+	}
+
+	for _, s := range secrets {
+		if s == "" || slices.Contains(t.secrets, s) {
+			continue
+		}
+
+		action.AddMask(s)
+		t.secrets = append(t.secrets, s)
+	}
+
+	return t
 }
 
 // RoundTrip implements the http.RoundTripper interface.
+//
+// Logging problems are reported, but never fail the request.
 func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
-	b, dumpErr := httputil.DumpRequestOut(req, true)
-	if dumpErr != nil {
-		t.action.Fatalf("%s", dumpErr)
-		return nil, dumpErr
+	if b, err := t.dumpRequest(req); err == nil {
+		t.action.Debugf("Request:\n%s", b)
+	} else {
+		t.action.Debugf("Failed to dump request: %s.", err)
 	}
 
-	t.action.Debugf("Request:\n%s", b)
-
 	resp, err := t.t.RoundTrip(req)
 	if resp == nil {
 		return nil, err
 	}
 
-	b, dumpErr = httputil.DumpResponse(resp, true)
-	if dumpErr != nil {
-		t.action.Fatalf("%s", dumpErr)
-		return nil, dumpErr
+	if b, dumpErr := t.dumpResponse(resp); dumpErr == nil {
+		t.action.Debugf("Response:\n%s", b)
+	} else {
+		t.action.Debugf("Failed to dump response: %s.", dumpErr)
 	}
 
-	t.action.Debugf("Response:\n%s", b)
-
 	return resp, err
 }
+
+// dumpRequest returns redacted request dump. Request body is preserved.
+func (t *transport) dumpRequest(req *http.Request) (string, error) {
+	var body []byte
+
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		req.Body = preservedBody(body, err)
+
+		if err != nil {
+			return "", err
+		}
+	}
+
+	r := req.Clone(req.Context())
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	r.URL = redactURL(req.URL)
+	redactHeaders(r.Header)
+
+	b, err := httputil.DumpRequestOut(r, false)
+	if err != nil {
+		return "", err
+	}
+
+	return t.redact(string(b) + truncate(body)), nil
+}
+
+// dumpResponse returns redacted response dump. Response body is preserved.
+func (t *transport) dumpResponse(resp *http.Response) (string, error) {
+	var body []byte
+
+	if resp.Body != nil && resp.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = preservedBody(body, err)
+
+		if err != nil {
+			return "", err
+		}
+	}
+
+	r := *resp
+	r.Body = nil
+	r.Header = resp.Header.Clone()
+	redactHeaders(r.Header)
+
+	b, err := httputil.DumpResponse(&r, false)
+	if err != nil {
+		return "", err
+	}
+
+	return t.redact(string(b) + truncate(body)), nil
+}
+
+// preservedBody returns a body that replaces the already read one.
+//
+// If reading failed, the returned body returns read bytes and then the same error,
+// so the caller does not silently get a truncated body.
+func preservedBody(body []byte, err error) io.ReadCloser {
+	if err == nil {
+		return io.NopCloser(bytes.NewReader(body))
+	}
+
+	return io.NopCloser(io.MultiReader(bytes.NewReader(body), errorReader{err: err}))
+}
+
+// errorReader is an io.Reader that always returns the given error.
+type errorReader struct {
+	err error
+}
+
+// Read implements io.Reader.
+func (r errorReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
+// redact replaces secrets and GitHub tokens in s.
+func (t *transport) redact(s string) string {
+	for _, secret := range t.secrets {
+		s = strings.ReplaceAll(s, secret, redacted)
+	}
+
+	return tokenRegexp.ReplaceAllString(s, redacted)
+}
+
+// redactHeaders replaces values of secret headers in place.
+func redactHeaders(h http.Header) {
+	for _, k := range secretHeaders {
+		if h.Get(k) != "" {
+			h.Set(k, redacted)
+		}
+	}
+}
+
+// redactURL returns a copy of u with user info and secret query parameters redacted.
+func redactURL(u *url.URL) *url.URL {
+	res := *u
+
+	if res.User != nil {
+		res.User = url.User(redacted)
+	}
+
+	q := res.Query()
+	for _, p := range secretParams {
+		if q.Has(p) {
+			q.Set(p, redacted)
+		}
+	}
+
+	res.RawQuery = q.Encode()
+
+	return &res
+}
+
+// truncate returns body as a string, truncated to maxDumpBody bytes with a size note.
+func truncate(body []byte) string {
+	if len(body) <= maxDumpBody {
+		return string(body)
+	}
+
+	return fmt.Sprintf("%s\n... (truncated, %d bytes total)", body[:maxDumpBody], len(body))
+}
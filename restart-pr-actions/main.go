@@ -12,11 +12,11 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package main
+// Package restartpractions implements `restart-pr-actions` action.
+package restartpractions
 
 import (
 	"context"
-	"flag"
 	"fmt"
 	"strconv"
 	"time"
@@ -25,24 +25,29 @@ import (
 	"github.com/sethvargo/go-githubactions"
 
 	"github.com/FerretDB/github-actions/internal"
+	"github.com/FerretDB/github-actions/internal/output"
 )
 
-func main() {
-	flag.Parse()
+// maxPollInterval is the maximal interval between workflow run status checks.
+const maxPollInterval = 30 * time.Second
 
-	ctx := context.Background()
-	action := githubactions.New()
-	client := internal.GitHubClient(action, "GITHUB_TOKEN")
+// Run runs the action.
+func Run(ctx context.Context, action *githubactions.Action) error {
+	client, err := internal.GitHubClient(ctx, action, "GITHUB_TOKEN")
+	if err != nil {
+		return fmt.Errorf("failed to create GitHub client: %w", err)
+	}
 
 	internal.DebugEnv(action)
 
-	if err := restart(ctx, action, client); err != nil {
-		action.Fatalf("%s", err)
-	}
+	err = restart(ctx, action, client)
+	internal.AddRateLimitSummary(action)
+
+	return err
 }
 
 // restart restarts actions for PR or branch in action inputs.
-func restart(ctx context.Context, action *githubactions.Action, client *github.Client) error {
+func restart(ctx context.Context, action *githubactions.Action, client *github.Client) (err error) {
 	owner := action.GetInput("owner")
 	if owner == "" {
 		return fmt.Errorf("restart: owner is required")
@@ -75,12 +80,28 @@ func restart(ctx context.Context, action *githubactions.Action, client *github.C
 
 	action.Infof("Waiting for workflows to finish ...")
 
+	// workflows usually take minutes, so poll less often over time to save API requests
+	interval := 3 * time.Second
+
+	runs := make(map[int64]*github.WorkflowRun, len(workflowRunIDs))
+	defer func() {
+		output.AddSummary(action, summary(workflowRunIDs, runs))
+
+		if resErr := output.SetResult(action, resultJSON(workflowRunIDs, runs)); resErr != nil && err == nil {
+			err = fmt.Errorf("restart: %w", resErr)
+		}
+	}()
+
 	var allCompleted bool
 	for !allCompleted {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
-		case <-time.After(3 * time.Second):
+		case <-time.After(interval):
+		}
+
+		if interval = interval * 3 / 2; interval > maxPollInterval {
+			interval = maxPollInterval
 		}
 
 		allCompleted = true
@@ -90,6 +111,8 @@ func restart(ctx context.Context, action *githubactions.Action, client *github.C
 				return fmt.Errorf("restart: %w", err)
 			}
 
+			runs[workflowRunID] = run
+
 			status := *run.Status
 			if status != "completed" {
 				allCompleted = false
@@ -107,6 +130,50 @@ func restart(ctx context.Context, action *githubactions.Action, client *github.C
 	return nil
 }
 
+// workflowRunJSON is a restarted workflow run encoded as `json` output.
+type workflowRunJSON struct {
+	ID         int64  `json:"id"`
+	Name       string `json:"name,omitempty"`
+	URL        string `json:"url,omitempty"`
+	Status     string `json:"status,omitempty"`
+	Conclusion string `json:"conclusion,omitempty"`
+}
+
+// resultJSON returns restarted workflow runs for `json` output.
+func resultJSON(workflowRunIDs []int64, runs map[int64]*github.WorkflowRun) map[string]any {
+	res := make([]workflowRunJSON, 0, len(workflowRunIDs))
+
+	for _, workflowRunID := range workflowRunIDs {
+		run := runs[workflowRunID]
+		res = append(res, workflowRunJSON{
+			ID:         workflowRunID,
+			Name:       run.GetName(),
+			URL:        run.GetHTMLURL(),
+			Status:     run.GetStatus(),
+			Conclusion: run.GetConclusion(),
+		})
+	}
+
+	return map[string]any{"workflow_runs": res}
+}
+
+// summary returns restarted workflow runs in Markdown.
+func summary(workflowRunIDs []int64, runs map[int64]*github.WorkflowRun) string {
+	table := output.NewTable("Workflow run", "Status", "Conclusion")
+
+	for _, workflowRunID := range workflowRunIDs {
+		run := runs[workflowRunID]
+		if run == nil {
+			table.Add(strconv.FormatInt(workflowRunID, 10), "", "")
+			continue
+		}
+
+		table.Add(fmt.Sprintf("[%s](%s)", run.GetName(), run.GetHTMLURL()), run.GetStatus(), run.GetConclusion())
+	}
+
+	return "Restarted workflow runs:\n\n" + table.String()
+}
+
 // collectWorkflowRunIDs collects workflow run IDs for a given branch or PR.
 func collectWorkflowRunIDs(ctx context.Context, action *githubactions.Action, client *github.Client, owner, repo, branch string, number int) ([]int64, error) {
 	var headSHA string
@@ -129,6 +196,7 @@ func collectWorkflowRunIDs(ctx context.Context, action *githubactions.Action, cl
 	// as it is available only for GitHub Apps, and GITHUB_TOKEN (which is a token set by GitHub App,
 	// see https://docs.github.com/en/actions/security-guides/automatic-token-authentication) is repo-scoped;
 	// we can't use it to access a different repository.
+	// GitHub App credentials (`app-id` input) could be used, but personal access tokens are still supported.
 	//
 	// Instead, we rely on the fact that Checks API's check run ID matches Actions API's job run ID,
 	// and use the latter, that is available for Personal Access Tokens.
@@ -12,10 +12,13 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package main
+// Package setupgo implements `setup-go` action.
+package setupgo
 
 import (
-	"flag"
+	"context"
+	"errors"
+	"fmt"
 	"io/fs"
 	"os"
 	"os/exec"
@@ -27,10 +30,11 @@ import (
 	"github.com/sethvargo/go-githubactions"
 
 	"github.com/FerretDB/github-actions/internal"
+	"github.com/FerretDB/github-actions/internal/output"
 )
 
 // tidyDir runs `go mod tidy -v` in the specified directory.
-func tidyDir(action *githubactions.Action, dir string) {
+func tidyDir(action *githubactions.Action, dir string) error {
 	cmd := exec.Command("go", "mod", "tidy", "-v")
 	cmd.Dir = dir
 	cmd.Stdout = os.Stdout
@@ -41,16 +45,18 @@ func tidyDir(action *githubactions.Action, dir string) {
 	action.Infof("Running `%s` in %s ...", strings.Join(cmd.Args, " "), cmd.Dir)
 
 	if err := cmd.Run(); err != nil {
-		action.Fatalf("%s", err)
+		return fmt.Errorf("tidyDir: %w", err)
 	}
 
 	action.Infof("Done in %s.", time.Since(start))
+
+	return nil
 }
 
 // checkEnv verifies that environment variables are set correctly.
 //
 //nolint:wsl // to group things better
-func checkEnv(action *githubactions.Action) (workspace, gocache string) {
+func checkEnv(action *githubactions.Action) (workspace, gocache string, err error) {
 	workspace = action.Getenv("GITHUB_WORKSPACE")
 	gopath := action.Getenv("GOPATH")
 	gocache = action.Getenv("GOCACHE")
@@ -60,59 +66,69 @@ func checkEnv(action *githubactions.Action) (workspace, gocache string) {
 	gotoolchain := action.Getenv("GOTOOLCHAIN")
 
 	if workspace == "" {
-		action.Fatalf("GITHUB_WORKSPACE is not set")
+		return "", "", errors.New("GITHUB_WORKSPACE is not set")
 	}
 	if gopath == "" {
-		action.Fatalf("GOPATH is not set")
+		return "", "", errors.New("GOPATH is not set")
 	}
 
 	if gocache == "" {
-		action.Fatalf("GOCACHE is not set")
+		return "", "", errors.New("GOCACHE is not set")
 	}
 	if golangciLintCache == "" {
-		action.Fatalf("GOLANGCI_LINT_CACHE is not set")
+		return "", "", errors.New("GOLANGCI_LINT_CACHE is not set")
 	}
 	if gomodcache == "" {
-		action.Fatalf("GOMODCACHE is not set")
+		return "", "", errors.New("GOMODCACHE is not set")
 	}
 
 	if !strings.HasPrefix(gocache, gopath) {
-		action.Fatalf("GOCACHE must be a subdirectory of GOPATH")
+		return "", "", errors.New("GOCACHE must be a subdirectory of GOPATH")
 	}
 	if !strings.HasPrefix(golangciLintCache, gocache) {
-		action.Fatalf("GOLANGCI_LINT_CACHE must be a subdirectory of GOCACHE")
+		return "", "", errors.New("GOLANGCI_LINT_CACHE must be a subdirectory of GOCACHE")
 	}
 	if strings.HasPrefix(gomodcache, gocache) {
-		action.Fatalf("GOMODCACHE must not be a subdirectory of GOCACHE")
+		return "", "", errors.New("GOMODCACHE must not be a subdirectory of GOCACHE")
 	}
 
 	if goproxy != "https://proxy.golang.org" {
-		action.Fatalf("GOPROXY must be explicitly set to `https://proxy.golang.org` (without `direct`)")
+		return "", "", errors.New("GOPROXY must be explicitly set to `https://proxy.golang.org` (without `direct`)")
 	}
 	if gotoolchain != "local" {
-		action.Fatalf("GOTOOLCHAIN must be explicitly set to `local` (without `auto`)")
+		return "", "", errors.New("GOTOOLCHAIN must be explicitly set to `local` (without `auto`)")
 	}
 
-	return
+	return workspace, gocache, nil
 }
 
-func main() {
-	start := time.Now()
-
-	flag.Parse()
+// resultJSON is a result encoded as `json` output.
+type resultJSON struct {
+	CacheWeek string   `json:"cache_week"`
+	CachePath string   `json:"cache_path"`
+	Modules   []string `json:"modules"` // never null
+}
 
-	action := githubactions.New()
+// Run runs the action.
+func Run(_ context.Context, action *githubactions.Action) error {
+	start := time.Now()
 
 	internal.DebugEnv(action)
-	workspace, gocache := checkEnv(action)
+
+	workspace, gocache, err := checkEnv(action)
+	if err != nil {
+		return err
+	}
 
 	// set parameters for the cache key
 	_, week := time.Now().UTC().ISOWeek() // starts on Monday
-	action.SetOutput("cache_week", "w"+strconv.Itoa(week))
+	cacheWeek := "w" + strconv.Itoa(week)
+	action.SetOutput("cache_week", cacheWeek)
 	action.SetOutput("cache_path", gocache)
 
 	// download modules in directories with `go.mod` file
-	err := filepath.Walk(workspace, func(path string, info fs.FileInfo, err error) error {
+	modules := []string{}
+	err = filepath.Walk(workspace, func(path string, info fs.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
@@ -131,13 +147,33 @@ func main() {
 			return nil
 		}
 
-		tidyDir(action, filepath.Dir(path))
+		dir := filepath.Dir(path)
+		if err = tidyDir(action, dir); err != nil {
+			return err
+		}
+
+		if rel, relErr := filepath.Rel(workspace, dir); relErr == nil {
+			dir = rel
+		}
+
+		modules = append(modules, dir)
 
 		return nil
 	})
 	if err != nil {
-		action.Fatalf("Error walking directory: %s", err)
+		return fmt.Errorf("error walking directory: %w", err)
+	}
+
+	res := &resultJSON{
+		CacheWeek: cacheWeek,
+		CachePath: gocache,
+		Modules:   modules,
+	}
+	if err = output.SetResult(action, res); err != nil {
+		return err
 	}
 
 	action.Infof("All done in %s.", time.Since(start))
+
+	return nil
 }